@@ -0,0 +1,38 @@
+package main
+
+// TvpName returns the quoted name of the table type the bulk sprocs take, i.e. [hr].[Employee_tvp]
+func (dataTable DataTable) TvpName() string {
+	return quoteName(getSchemaName(dataTable)) + "." + quoteName(dataTable.name+"_tvp")
+}
+
+// TvpColumns are the columns of the table type, everything but the computed ones.
+// The identity and rowversion are NULL for the records that haven't been inserted
+// yet. The bulk insert leaves them out, the bulk upsert matches the rest on them.
+func (dataTable DataTable) TvpColumns() []Column {
+	columns := make([]Column, 0)
+	for _, column := range dataTable.columns {
+		if column.is_computed {
+			continue
+		}
+		if column.is_identity || isRowVersion(column) {
+			column.is_nullable = true
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// CanBulkUpsert tells us if the bulk upsert sproc can find the records again. The
+// table type has no computed columns, so a key with one in it can't be matched and
+// those tables only get the bulk insert.
+func (dataTable DataTable) CanBulkUpsert() bool {
+	if !hasKey(dataTable) {
+		return false
+	}
+	for _, column := range getKeyColumns(dataTable) {
+		if column.is_computed {
+			return false
+		}
+	}
+	return true
+}
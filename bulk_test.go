@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestCanBulkUpsert(t *testing.T) {
+	tests := []struct {
+		dataTable DataTable
+		expected  bool
+	}{
+		{orderLineTable(), true},
+		// the identity is in the table type, NULL for the new records
+		{employeeTable(), true},
+		{appLogTable(), false},
+		// the table type has no computed columns to match on
+		{DataTable{schema: "dbo", name: "t", columns: []Column{{column_name: "a", is_computed: true, key_ordinal: 1}, {column_name: "b"}}}, false},
+	}
+
+	for _, test := range tests {
+		if canBulkUpsert := test.dataTable.CanBulkUpsert(); canBulkUpsert != test.expected {
+			t.Errorf("%s CanBulkUpsert() = %t, expected %t", test.dataTable.name, canBulkUpsert, test.expected)
+		}
+	}
+
+	if name := orderLineTable().TvpName(); name != "[sales].[OrderLine_tvp]" {
+		t.Errorf("TvpName() = %s, expected [sales].[OrderLine_tvp]", name)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Hand-written code goes between the custom markers in the generated class,
+// i.e.
+//
+//	// <custom>
+//	public bool IsManager() { ... }
+//	// </custom>
+//
+// and is carried over when the class is regenerated. Anything after <custom
+// names the region, so a file can have more than one.
+const customStart = "// <custom"
+const customEnd = "// </custom>"
+
+// isCustomStart tells us if the line opens a custom region
+func isCustomStart(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, customStart) && strings.HasSuffix(line, ">")
+}
+
+// isCustomEnd tells us if the line closes a custom region
+func isCustomEnd(line string) bool {
+	return strings.TrimSpace(line) == customEnd
+}
+
+// findCustomRegions returns the code inside each custom region of the text,
+// by the region's marker. The lines keep their endings so they go back unchanged.
+func findCustomRegions(text string) (map[string][]string, error) {
+	regions := make(map[string][]string)
+
+	name := ""
+	inside := false
+	var body bytes.Buffer
+
+	for _, line := range splitLines(text) {
+		switch {
+		case isCustomEnd(line):
+			if !inside {
+				return nil, fmt.Errorf("%s without a %s>", customEnd, customStart)
+			}
+			regions[name] = append(regions[name], body.String())
+			inside = false
+		case isCustomStart(line):
+			if inside {
+				return nil, fmt.Errorf("%s starts inside %s", strings.TrimSpace(line), name)
+			}
+			name = strings.TrimSpace(line)
+			inside = true
+			body.Reset()
+		case inside:
+			body.WriteString(line)
+		}
+	}
+
+	if inside {
+		return nil, fmt.Errorf("%s is missing its %s", name, customEnd)
+	}
+	return regions, nil
+}
+
+// preserveCustomRegions copies the custom regions of the existing file into the
+// generated code. A region the templates no longer have is an error, rather than
+// losing somebody's code.
+func preserveCustomRegions(fileName string, generated string) (string, error) {
+	existing, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return generated, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	regions, err := findCustomRegions(string(existing))
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", fileName, err)
+	}
+
+	var buffer bytes.Buffer
+
+	inside := false
+	for _, line := range splitLines(generated) {
+		switch {
+		case isCustomStart(line):
+			buffer.WriteString(line)
+			name := strings.TrimSpace(line)
+			if bodies := regions[name]; len(bodies) > 0 {
+				// the existing code replaces whatever the template had in there
+				buffer.WriteString(bodies[0])
+				regions[name] = bodies[1:]
+				inside = true
+			}
+		case isCustomEnd(line):
+			buffer.WriteString(line)
+			inside = false
+		case !inside:
+			buffer.WriteString(line)
+		}
+	}
+
+	for name, bodies := range regions {
+		for _, body := range bodies {
+			if strings.TrimSpace(body) != "" {
+				return "", fmt.Errorf("%s: the %s region isn't generated any more, move its code before regenerating", fileName, name)
+			}
+		}
+	}
+
+	return buffer.String(), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindCustomRegions(t *testing.T) {
+	text := "using System;\n// <custom usings>\nusing Foo;\n// </custom>\nclass X {\n\t// <custom>\n\tint y;\n\t// </custom>\n}\n"
+
+	regions, err := findCustomRegions(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"// <custom usings>": {"using Foo;\n"},
+		"// <custom>":        {"\tint y;\n"},
+	}
+	if !reflect.DeepEqual(regions, expected) {
+		t.Errorf("findCustomRegions() = %q, expected %q", regions, expected)
+	}
+
+	for _, bad := range []string{
+		"// <custom>\nint y;\n",
+		"int y;\n// </custom>\n",
+		"// <custom>\n// <custom other>\n// </custom>\n",
+	} {
+		if _, err := findCustomRegions(bad); err == nil {
+			t.Errorf("findCustomRegions(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPreserveCustomRegions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "Employee.cs")
+
+	generated := "class Employee {\n\tint a;\n\t// <custom>\n\t// </custom>\n}\n"
+
+	// nothing to keep when the file isn't there yet
+	code, err := preserveCustomRegions(fileName, generated)
+	if err != nil || code != generated {
+		t.Fatalf("preserveCustomRegions() = %q, %v without a file", code, err)
+	}
+
+	existing := "class Employee {\n\tint old;\n\t// <custom>\n\tbool IsManager() { return true; }\n\t// </custom>\n}\n"
+	if err := ioutil.WriteFile(fileName, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, err = preserveCustomRegions(fileName, generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "class Employee {\n\tint a;\n\t// <custom>\n\tbool IsManager() { return true; }\n\t// </custom>\n}\n"
+	if code != expected {
+		t.Errorf("preserveCustomRegions() = %q, expected %q", code, expected)
+	}
+
+	// a region the template dropped would lose the code
+	if _, err := preserveCustomRegions(fileName, "class Employee {\n}\n"); err == nil {
+		t.Error("preserveCustomRegions() should fail when a region with code isn't generated")
+	}
+}
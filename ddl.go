@@ -0,0 +1,675 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ddlProvider serves the tables defined by CREATE TABLE statements in .sql
+// files, so code can be generated before the table exists on any server
+type ddlProvider struct {
+	memoryProvider
+}
+
+// newDdlProvider parses the given .sql files. A directory means every .sql
+// file in it, in name order, so a later CREATE TABLE replaces an earlier one.
+func newDdlProvider(paths []string) (*ddlProvider, error) {
+	fileNames := make([]string, 0)
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			fileNames = append(fileNames, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.sql"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		fileNames = append(fileNames, matches...)
+	}
+
+	provider := &ddlProvider{}
+	for _, fileName := range fileNames {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		dataTables, err := parseDdl(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fileName, err)
+		}
+		for _, dataTable := range dataTables {
+			provider.addDataTable(dataTable)
+		}
+	}
+	return provider, nil
+}
+
+// addDataTable adds the table, replacing any earlier one with the same name
+func (provider *ddlProvider) addDataTable(dataTable DataTable) {
+	for i, existing := range provider.dataTables {
+		if strings.EqualFold(getDataTableFullName(existing), getDataTableFullName(dataTable)) {
+			provider.dataTables[i] = dataTable
+			return
+		}
+	}
+	provider.dataTables = append(provider.dataTables, dataTable)
+}
+
+// ddlToken is a word, quoted identifier, literal or punctuation from the script.
+// start and end are offsets into the script so expressions can be copied out as written.
+type ddlToken struct {
+	text   string
+	quoted bool // a [bracketed] or "quoted" identifier, never a keyword
+	start  int
+	end    int
+}
+
+// is tells us if the token is the given keyword or punctuation
+func (token ddlToken) is(keyword string) bool {
+	return !token.quoted && strings.EqualFold(token.text, keyword)
+}
+
+// tokenizeDdl splits a T-SQL script into tokens, dropping whitespace and comments
+func tokenizeDdl(script string) ([]ddlToken, error) {
+	tokens := make([]ddlToken, 0)
+	runes := []rune(script)
+
+	// offsets are in runes until the end, then converted to bytes for slicing
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			continue
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; !(i+1 < len(runes) && runes[i] == '*' && runes[i+1] == '/'); i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated comment")
+				}
+			}
+			i += 2
+			continue
+		case r == '[' || r == '"':
+			closing := ']'
+			if r == '"' {
+				closing = '"'
+			}
+			var name strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated identifier")
+				}
+				if runes[i] == closing {
+					// a doubled closing character is an escaped one
+					if i+1 < len(runes) && runes[i+1] == closing {
+						name.WriteRune(closing)
+						i++
+						continue
+					}
+					break
+				}
+				name.WriteRune(runes[i])
+			}
+			i++
+			tokens = append(tokens, ddlToken{text: name.String(), quoted: true, start: start, end: i})
+			continue
+		case r == '\'' || ((r == 'N' || r == 'n') && i+1 < len(runes) && runes[i+1] == '\''):
+			if r != '\'' {
+				i++
+			}
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			i++
+		case unicode.IsDigit(r):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+		case isDdlWordRune(r):
+			for i < len(runes) && isDdlWordRune(runes[i]) {
+				i++
+			}
+		default:
+			i++
+		}
+
+		tokens = append(tokens, ddlToken{text: string(runes[start:i]), start: start, end: i})
+	}
+
+	// turn the rune offsets into byte offsets, a byte that isn't valid UTF-8 is
+	// a rune of its own, but one byte wide rather than the width of U+FFFD
+	offsets := make([]int, 0, len(runes)+1)
+	for n := 0; n < len(script); {
+		offsets = append(offsets, n)
+		_, width := utf8.DecodeRuneInString(script[n:])
+		n += width
+	}
+	offsets = append(offsets, len(script))
+	for i := range tokens {
+		tokens[i].start = offsets[tokens[i].start]
+		tokens[i].end = offsets[tokens[i].end]
+	}
+
+	return tokens, nil
+}
+
+func isDdlWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '@' || r == '#' || r == '$'
+}
+
+// parseDdl finds the CREATE TABLE statements in a script and builds a dataTable for each.
+// Everything else in the script is skipped.
+func parseDdl(script string) ([]DataTable, error) {
+	tokens, err := tokenizeDdl(script)
+	if err != nil {
+		return nil, err
+	}
+
+	dataTables := make([]DataTable, 0)
+	for i := 0; i+2 < len(tokens); i++ {
+		if !(tokens[i].is("create") && tokens[i+1].is("table")) {
+			continue
+		}
+
+		parser := ddlParser{script: script, tokens: tokens, pos: i + 2}
+		dataTable, err := parser.parseCreateTable()
+		if err != nil {
+			return nil, err
+		}
+		// temp tables aren't anything we'd generate for
+		if !strings.HasPrefix(dataTable.name, "#") {
+			dataTables = append(dataTables, dataTable)
+		}
+		i = parser.pos - 1
+	}
+
+	return dataTables, nil
+}
+
+type ddlParser struct {
+	script string
+	tokens []ddlToken
+	pos    int
+}
+
+func (parser *ddlParser) peek() ddlToken {
+	if parser.pos < len(parser.tokens) {
+		return parser.tokens[parser.pos]
+	}
+	return ddlToken{start: len(parser.script), end: len(parser.script)}
+}
+
+func (parser *ddlParser) next() ddlToken {
+	token := parser.peek()
+	parser.pos++
+	return token
+}
+
+// accept moves past the keywords if they're next, in order
+func (parser *ddlParser) accept(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if parser.pos+i >= len(parser.tokens) || !parser.tokens[parser.pos+i].is(keyword) {
+			return false
+		}
+	}
+	parser.pos += len(keywords)
+	return true
+}
+
+func (parser *ddlParser) expect(keyword string) error {
+	if !parser.accept(keyword) {
+		return fmt.Errorf("expected %s but found %q", keyword, parser.peek().text)
+	}
+	return nil
+}
+
+func (parser *ddlParser) done() bool {
+	return parser.pos >= len(parser.tokens)
+}
+
+// skipGroup moves past a parenthesised group, if that's what's next
+func (parser *ddlParser) skipGroup() {
+	if !parser.peek().is("(") {
+		return
+	}
+	depth := 0
+	for !parser.done() {
+		token := parser.next()
+		if token.is("(") {
+			depth++
+		} else if token.is(")") {
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// atElementEnd tells us if we've reached the comma or closing paren after a
+// column or constraint definition
+func (parser *ddlParser) atElementEnd() bool {
+	return parser.done() || parser.peek().is(",") || parser.peek().is(")")
+}
+
+// parseName reads a possibly qualified name ([dbo].[Employee], dbo.Employee) and
+// returns the last part of it
+func (parser *ddlParser) parseName() (string, error) {
+	token := parser.next()
+	if token.text == "" || (!token.quoted && !isDdlWordRune([]rune(token.text)[0])) {
+		return "", fmt.Errorf("expected a name but found %q", token.text)
+	}
+	name := token.text
+	for parser.accept(".") {
+		name = parser.next().text
+	}
+	return name, nil
+}
+
+// parseQualifiedName reads a name like [hr].[Employee] and returns the schema,
+// dbo if there isn't one, and the name. A database in front is ignored.
+func (parser *ddlParser) parseQualifiedName() (string, string, error) {
+	token := parser.next()
+	if token.text == "" || (!token.quoted && !isDdlWordRune([]rune(token.text)[0])) {
+		return "", "", fmt.Errorf("expected a name but found %q", token.text)
+	}
+	schema, name := defaultSchema, token.text
+	for parser.accept(".") {
+		schema, name = name, parser.next().text
+	}
+	return schema, name, nil
+}
+
+// parseCreateTable reads the table name and definition list after CREATE TABLE
+func (parser *ddlParser) parseCreateTable() (DataTable, error) {
+	dataTable := DataTable{}
+
+	schema, name, err := parser.parseQualifiedName()
+	if err != nil {
+		return dataTable, err
+	}
+	dataTable.schema = schema
+	dataTable.name = name
+
+	if err := parser.expect("("); err != nil {
+		return dataTable, fmt.Errorf("table %s: %s", name, err)
+	}
+
+	primaryKey := make([]string, 0)
+	for {
+		key, err := parser.parseTableElement(&dataTable)
+		if err != nil {
+			return dataTable, fmt.Errorf("table %s: %s", name, err)
+		}
+		primaryKey = append(primaryKey, key...)
+
+		if parser.accept(",") {
+			continue
+		}
+		if err := parser.expect(")"); err != nil {
+			return dataTable, fmt.Errorf("table %s: %s", name, err)
+		}
+		break
+	}
+
+	for ordinal, keyName := range primaryKey {
+		found := false
+		for i := range dataTable.columns {
+			if strings.EqualFold(dataTable.columns[i].column_name, keyName) {
+				dataTable.columns[i].key_ordinal = ordinal + 1
+				dataTable.columns[i].is_nullable = false
+				found = true
+			}
+		}
+		if !found {
+			return dataTable, fmt.Errorf("table %s: primary key column %s is not defined", name, keyName)
+		}
+	}
+
+	if len(dataTable.columns) == 0 {
+		return dataTable, fmt.Errorf("table %s has no columns", name)
+	}
+
+	return dataTable, nil
+}
+
+// parseTableElement reads one column or table constraint, adding columns to the
+// dataTable. It returns the primary key column names if the element defines one.
+func (parser *ddlParser) parseTableElement(dataTable *DataTable) ([]string, error) {
+	if parser.accept("constraint") {
+		parser.next() // the constraint name
+	}
+
+	switch {
+	case parser.accept("primary", "key"):
+		return parser.parseKeyColumns()
+	case parser.peek().is("unique"), parser.peek().is("check"), parser.peek().is("foreign"),
+		parser.peek().is("index"), parser.peek().is("period"):
+		parser.skipElement()
+		return nil, nil
+	}
+
+	column, isKey, err := parser.parseColumn()
+	if err != nil {
+		return nil, err
+	}
+	column.dataTable_name = dataTable.name
+	column.column_id = len(dataTable.columns) + 1
+	dataTable.columns = append(dataTable.columns, column)
+
+	if isKey {
+		return []string{column.column_name}, nil
+	}
+	return nil, nil
+}
+
+// parseKeyColumns reads the ([a] ASC, [b] DESC) list of a PRIMARY KEY constraint
+func (parser *ddlParser) parseKeyColumns() ([]string, error) {
+	parser.accept("clustered")
+	parser.accept("nonclustered")
+
+	if err := parser.expect("("); err != nil {
+		return nil, err
+	}
+
+	keyNames := make([]string, 0)
+	for {
+		keyNames = append(keyNames, parser.next().text)
+		if !parser.accept("asc") {
+			parser.accept("desc")
+		}
+		if parser.accept(",") {
+			continue
+		}
+		if err := parser.expect(")"); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	// WITH (...), ON [PRIMARY] and the like
+	parser.skipElement()
+	return keyNames, nil
+}
+
+// skipElement moves to the end of the current column or constraint definition
+func (parser *ddlParser) skipElement() {
+	for !parser.atElementEnd() {
+		if parser.peek().is("(") {
+			parser.skipGroup()
+		} else {
+			parser.next()
+		}
+	}
+}
+
+// parseColumn reads a column definition, including its options.
+// It also tells us if the column was marked PRIMARY KEY.
+func (parser *ddlParser) parseColumn() (Column, bool, error) {
+	column := Column{is_nullable: true}
+	isKey := false
+
+	column.column_name = parser.next().text
+
+	if parser.accept("as") {
+		// computed column, we don't know the type without evaluating the expression
+		column.is_computed = true
+		column.data_type = "sql_variant"
+		if _, err := parser.parseExpression(); err != nil {
+			return column, false, fmt.Errorf("column %s: %s", column.column_name, err)
+		}
+	} else {
+		if err := parser.parseDataType(&column); err != nil {
+			return column, false, fmt.Errorf("column %s: %s", column.column_name, err)
+		}
+	}
+
+	for !parser.atElementEnd() {
+		switch {
+		case parser.accept("identity"):
+			column.is_identity = true
+			column.is_nullable = false
+			parser.skipGroup()
+		case parser.accept("not", "null"):
+			column.is_nullable = false
+		case parser.accept("null"):
+			column.is_nullable = true
+		case parser.accept("primary", "key"):
+			isKey = true
+			column.is_nullable = false
+			parser.accept("clustered")
+			parser.accept("nonclustered")
+		case parser.accept("default"):
+			expression, err := parser.parseExpression()
+			if err != nil {
+				return column, false, fmt.Errorf("column %s: %s", column.column_name, err)
+			}
+			column.default_value = expression
+		case parser.accept("constraint"), parser.accept("collate"):
+			parser.next()
+		case parser.accept("references"):
+			parser.parseName()
+			parser.skipGroup()
+		case parser.accept("check"):
+			parser.skipGroup()
+		case parser.peek().is("("):
+			parser.skipGroup()
+		default:
+			// PERSISTED, SPARSE, ROWGUIDCOL, UNIQUE, ON DELETE CASCADE ...
+			parser.next()
+		}
+	}
+
+	return column, isKey, nil
+}
+
+// parseExpression reads a DEFAULT or computed column expression and returns it as written
+func (parser *ddlParser) parseExpression() (string, error) {
+	start := parser.peek().start
+
+	// a leading sign goes with the number
+	if parser.peek().is("-") || parser.peek().is("+") {
+		parser.next()
+	}
+	if parser.atElementEnd() {
+		return "", fmt.Errorf("expected an expression but found %q", parser.peek().text)
+	}
+	if parser.peek().is("(") {
+		parser.skipGroup()
+	} else {
+		parser.next()
+		// a function call like getdate()
+		parser.skipGroup()
+	}
+
+	end := parser.tokens[parser.pos-1].end
+	return strings.TrimSpace(parser.script[start:end]), nil
+}
+
+// parseDataType reads the type and its size, storing them the way sys.columns does:
+// max_length in bytes (-1 for MAX), precision and scale for numbers and times
+func (parser *ddlParser) parseDataType(column *Column) error {
+	name, err := parser.parseName()
+	if err != nil {
+		return err
+	}
+	column.data_type = strings.ToLower(name)
+
+	switch column.data_type {
+	case "rowversion":
+		column.data_type = "timestamp"
+	case "dec":
+		column.data_type = "decimal"
+	case "integer":
+		column.data_type = "int"
+	case "double":
+		parser.accept("precision")
+		column.data_type = "float"
+	}
+
+	if size, ok := ddlTypeSizes[column.data_type]; ok {
+		column.max_length = size.max_length
+		column.precision = size.precision
+		column.scale = size.scale
+	}
+
+	args := make([]string, 0)
+	if parser.accept("(") {
+		for {
+			args = append(args, strings.ToLower(parser.next().text))
+			if parser.accept(",") {
+				continue
+			}
+			if err := parser.expect(")"); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	number := func(i int) (int, error) {
+		n, err := strconv.Atoi(args[i])
+		if err != nil {
+			return 0, fmt.Errorf("bad size %q for %s", args[i], column.data_type)
+		}
+		return n, nil
+	}
+
+	switch column.data_type {
+	case "char", "varchar", "binary", "varbinary", "nchar", "nvarchar":
+		length := 1 // what SqlServer gives you without a length
+		if len(args) > 0 {
+			if args[0] == "max" {
+				length = -1
+			} else if length, err = number(0); err != nil {
+				return err
+			}
+		}
+		if length > 0 && (column.data_type == "nchar" || column.data_type == "nvarchar") {
+			length *= 2
+		}
+		column.max_length = length
+	case "decimal", "numeric":
+		column.precision, column.scale = 18, 0
+		if len(args) > 0 {
+			if column.precision, err = number(0); err != nil {
+				return err
+			}
+		}
+		if len(args) > 1 {
+			if column.scale, err = number(1); err != nil {
+				return err
+			}
+		}
+		column.max_length = decimalStorageSize(column.precision)
+	case "float":
+		if len(args) > 0 {
+			n, err := number(0)
+			if err != nil {
+				return err
+			}
+			// SqlServer stores float(1-24) as a real
+			if n <= 24 {
+				column.data_type = "real"
+				column.max_length, column.precision = 4, 24
+			}
+		}
+	case "time", "datetime2", "datetimeoffset":
+		if len(args) > 0 {
+			if column.scale, err = number(0); err != nil {
+				return err
+			}
+		}
+		// the precision counts the digits of the fraction (and its point),
+		// the storage grows with it
+		base := map[string]int{"time": 8, "datetime2": 19, "datetimeoffset": 26}[column.data_type]
+		column.precision = base + column.scale
+		if column.scale > 0 {
+			column.precision++
+		}
+		column.max_length = ddlTypeSizes[column.data_type].max_length
+		if column.scale <= 2 {
+			column.max_length -= 2
+		} else if column.scale <= 4 {
+			column.max_length--
+		}
+	case "timestamp":
+		column.is_nullable = false
+	}
+
+	return nil
+}
+
+// decimalStorageSize is the max_length SqlServer reports for a decimal of the precision
+func decimalStorageSize(precision int) int {
+	switch {
+	case precision <= 9:
+		return 5
+	case precision <= 19:
+		return 9
+	case precision <= 28:
+		return 13
+	}
+	return 17
+}
+
+// ddlTypeSizes are the sys.columns sizes of the types that don't take a length
+var ddlTypeSizes = map[string]struct {
+	max_length int
+	precision  int
+	scale      int
+}{
+	"bit":              {1, 1, 0},
+	"tinyint":          {1, 3, 0},
+	"smallint":         {2, 5, 0},
+	"int":              {4, 10, 0},
+	"bigint":           {8, 19, 0},
+	"smallmoney":       {4, 10, 4},
+	"money":            {8, 19, 4},
+	"real":             {4, 24, 0},
+	"float":            {8, 53, 0},
+	"date":             {3, 10, 0},
+	"time":             {5, 16, 7},
+	"smalldatetime":    {4, 16, 0},
+	"datetime":         {8, 23, 3},
+	"datetime2":        {8, 27, 7},
+	"datetimeoffset":   {10, 34, 7},
+	"uniqueidentifier": {16, 0, 0},
+	"timestamp":        {8, 0, 0},
+	"text":             {16, 0, 0},
+	"ntext":            {16, 0, 0},
+	"image":            {16, 0, 0},
+	"xml":              {-1, 0, 0},
+	"sql_variant":      {8016, 0, 0},
+	"hierarchyid":      {892, 0, 0},
+	"geography":        {-1, 0, 0},
+	"geometry":         {-1, 0, 0},
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeDdl(t *testing.T) {
+	script := "CREATE TABLE [dbo].[Order ]]x] ( -- the orders\n\tNote nvarchar(10) DEFAULT N'it''s' /* a comment */\n)"
+
+	tokens, err := tokenizeDdl(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := make([]string, 0)
+	quoted := make([]string, 0)
+	for _, token := range tokens {
+		texts = append(texts, token.text)
+		if token.quoted {
+			quoted = append(quoted, token.text)
+		}
+	}
+
+	expected := []string{"CREATE", "TABLE", "dbo", ".", "Order ]x", "(", "Note", "nvarchar", "(", "10", ")", "DEFAULT", "N'it''s'", ")"}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("tokenizeDdl() = %q, expected %q", texts, expected)
+	}
+	if expected := []string{"dbo", "Order ]x"}; !reflect.DeepEqual(quoted, expected) {
+		t.Errorf("tokenizeDdl() quoted %q, expected %q", quoted, expected)
+	}
+
+	// the offsets are bytes into the script, so the text can be sliced back out
+	if last := tokens[len(tokens)-2]; script[last.start:last.end] != "N'it''s'" {
+		t.Errorf("the string token slices to %q", script[last.start:last.end])
+	}
+
+	for _, bad := range []string{"/* open", "[open", "'open", "\"open"} {
+		if _, err := tokenizeDdl(bad); err == nil {
+			t.Errorf("tokenizeDdl(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseDdl(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected []DataTable
+	}{
+		{
+			"types",
+			"CREATE TABLE t (a nvarchar(50) NOT NULL, b varchar(max), c decimal(10, 3), d float(24), e datetime2(3), f rowversion, g dec)",
+			[]DataTable{{schema: "dbo", name: "t", columns: []Column{
+				{dataTable_name: "t", column_name: "a", data_type: "nvarchar", max_length: 100, column_id: 1},
+				{dataTable_name: "t", column_name: "b", data_type: "varchar", max_length: -1, column_id: 2, is_nullable: true},
+				{dataTable_name: "t", column_name: "c", data_type: "decimal", max_length: 9, precision: 10, scale: 3, column_id: 3, is_nullable: true},
+				{dataTable_name: "t", column_name: "d", data_type: "real", max_length: 4, precision: 24, column_id: 4, is_nullable: true},
+				{dataTable_name: "t", column_name: "e", data_type: "datetime2", max_length: 7, precision: 23, scale: 3, column_id: 5, is_nullable: true},
+				{dataTable_name: "t", column_name: "f", data_type: "timestamp", max_length: 8, column_id: 6},
+				{dataTable_name: "t", column_name: "g", data_type: "decimal", max_length: 9, precision: 18, column_id: 7, is_nullable: true},
+			}}},
+		},
+		{
+			"identity and inline primary key",
+			"CREATE TABLE hr.Employee (EmployeeId int IDENTITY(1,1) PRIMARY KEY CLUSTERED, Name nvarchar(10) NULL)",
+			[]DataTable{{schema: "hr", name: "Employee", columns: []Column{
+				{dataTable_name: "Employee", column_name: "EmployeeId", data_type: "int", max_length: 4, precision: 10, column_id: 1, is_identity: true, key_ordinal: 1},
+				{dataTable_name: "Employee", column_name: "Name", data_type: "nvarchar", max_length: 20, column_id: 2, is_nullable: true},
+			}}},
+		},
+		{
+			"computed columns and defaults",
+			"CREATE TABLE t (a int NOT NULL CONSTRAINT DF_a DEFAULT -1, b datetime DEFAULT getdate(), c AS (a + 1) PERSISTED)",
+			[]DataTable{{schema: "dbo", name: "t", columns: []Column{
+				{dataTable_name: "t", column_name: "a", data_type: "int", max_length: 4, precision: 10, column_id: 1, default_value: "-1"},
+				{dataTable_name: "t", column_name: "b", data_type: "datetime", max_length: 8, precision: 23, scale: 3, column_id: 2, is_nullable: true, default_value: "getdate()"},
+				{dataTable_name: "t", column_name: "c", data_type: "sql_variant", column_id: 3, is_computed: true, is_nullable: true},
+			}}},
+		},
+		{
+			"table level primary key",
+			"CREATE TABLE [sales].[OrderLine] ([OrderId] int NOT NULL, [LineNo] smallint,\n" +
+				"CONSTRAINT [PK_OrderLine] PRIMARY KEY CLUSTERED ([OrderId] ASC, [LineNo] DESC) WITH (PAD_INDEX = OFF) ON [PRIMARY]) ON [PRIMARY]",
+			[]DataTable{{schema: "sales", name: "OrderLine", columns: []Column{
+				{dataTable_name: "OrderLine", column_name: "OrderId", data_type: "int", max_length: 4, precision: 10, column_id: 1, key_ordinal: 1},
+				{dataTable_name: "OrderLine", column_name: "LineNo", data_type: "smallint", max_length: 2, precision: 5, column_id: 2, key_ordinal: 2},
+			}}},
+		},
+		{
+			"temp tables are left out",
+			"CREATE TABLE #tmp (a int)\ngo\nselect 1\nCREATE TABLE b (x bit)",
+			[]DataTable{{schema: "dbo", name: "b", columns: []Column{
+				{dataTable_name: "b", column_name: "x", data_type: "bit", max_length: 1, precision: 1, column_id: 1, is_nullable: true},
+			}}},
+		},
+	}
+
+	for _, test := range tests {
+		dataTables, err := parseDdl(test.script)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(dataTables, test.expected) {
+			t.Errorf("%s: parseDdl() =\n%+v\nexpected\n%+v", test.name, dataTables, test.expected)
+		}
+	}
+}
+
+func TestParseDdlErrors(t *testing.T) {
+	for _, script := range []string{
+		"CREATE TABLE x (a int DEFAULT",
+		"CREATE TABLE x (a int DEFAULT, b int)",
+		"CREATE TABLE x (a AS",
+		"CREATE TABLE x (a int",
+		"CREATE TABLE x ()",
+		"CREATE TABLE x (a varchar(abc))",
+		"CREATE TABLE x (a int, PRIMARY KEY (b))",
+		"CREATE TABLE x (a nvarchar(10) DEFAULT 'abc",
+		"CREATE TABLE (a int)",
+	} {
+		if _, err := parseDdl(script); err == nil {
+			t.Errorf("parseDdl(%q) should fail", script)
+		}
+	}
+}
+
+// TestParseDdlTruncated makes sure a script cut off anywhere gives an error, not a panic
+func TestParseDdlTruncated(t *testing.T) {
+	script := "CREATE TABLE [dbo].[Employee] (\n" +
+		"\t[EmployeeId] int IDENTITY(1,1) NOT NULL,\n" +
+		"\tHourlyWage decimal(10, 3) NOT NULL CONSTRAINT DF_Wage DEFAULT ((0)),\n" +
+		"\tHireDate datetime2(3) NULL DEFAULT getdate(),\n" +
+		"\tFullName AS ([Name] + N' ') PERSISTED,\n" +
+		"\tCONSTRAINT [PK_Employee] PRIMARY KEY CLUSTERED ([EmployeeId] ASC)\n" +
+		")"
+
+	// a script saved as Latin-1 rather than UTF-8 has bytes that aren't valid UTF-8
+	latin1 := "CREATE TABLE t (a varchar(10) DEFAULT 'caf\xe9', b int DEFAULT (1))"
+	dataTables, err := parseDdl(latin1)
+	if err != nil {
+		t.Fatalf("parseDdl(%q): %s", latin1, err)
+	}
+	if columns := dataTables[0].columns; len(columns) != 2 || columns[0].default_value != "'caf\xe9'" || columns[1].default_value != "(1)" {
+		t.Errorf("parseDdl(%q) = %+v", latin1, columns)
+	}
+
+	for i := range script {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("parseDdl(%q) panicked: %v", script[:i], r)
+				}
+			}()
+			parseDdl(script[:i])
+		}()
+	}
+}
+
+func TestDdlProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ddl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scripts := map[string]string{
+		"1_tables.sql": "CREATE TABLE Employee (EmployeeId int)\ngo\nCREATE TABLE Dept (DeptId int)",
+		"2_change.sql": "CREATE TABLE employee (EmployeeId int, Name nvarchar(50))",
+		"notes.txt":    "CREATE TABLE Ignored (x int)",
+	}
+	for fileName, script := range scripts {
+		if err := ioutil.WriteFile(filepath.Join(dir, fileName), []byte(script), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	provider, err := newDdlProvider([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataTableNames, err := provider.ListDataTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"dbo.employee", "dbo.Dept"}; !reflect.DeepEqual(dataTableNames, expected) {
+		t.Errorf("ListDataTables() = %q, expected %q", dataTableNames, expected)
+	}
+
+	// the later file replaces the earlier CREATE TABLE
+	dataTable, err := provider.LoadDataTable("Employee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataTable.columns) != 2 {
+		t.Errorf("Employee has %d columns, expected the 2 from 2_change.sql", len(dataTable.columns))
+	}
+
+	if _, err := newDdlProvider([]string{filepath.Join(dir, "missing.sql")}); err == nil {
+		t.Error("a missing file should fail")
+	}
+}
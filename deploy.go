@@ -0,0 +1,112 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// deploy runs the generated scripts against -server/-database, so nobody has to
+// paste CREATE_<table>.sql into SSMS. Everything runs in one transaction, if any
+// batch fails nothing is changed. With -dry-run it's all rolled back at the end,
+// which checks the scripts without touching the sprocs. -rollback saves the
+// deployed versions first, so the deploy can be reverted.
+//
+// To try it out on a local container:
+//
+//	docker run -e ACCEPT_EULA=Y -e SA_PASSWORD=Passw0rd! -p 1433:1433 mcr.microsoft.com/mssql/server
+//	tzSproc -server localhost -user sa -password Passw0rd! -database tempdb -ddl ddl -all deploy
+func deploy() {
+	provider, err := newSchemaProvider()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer provider.Close()
+
+	dataTableNames, unmatched, err := findDataTables(provider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(unmatched) > 0 {
+		log.Fatalf("no tables matched %s", strings.Join(unmatched, ", "))
+	}
+	if len(dataTableNames) == 0 {
+		log.Fatal("no tables matched; use -table or -all")
+	}
+
+	conn, err := openConnection()
+	if err != nil {
+		log.Fatalf("Open connection failed: %s", err)
+	}
+	defer conn.Close()
+
+	// generate everything first, a template error shouldn't leave half a deploy
+	scripts := make([]string, 0, len(dataTableNames))
+	for _, dataTableName := range dataTableNames {
+		dataTable, err := loadDataTable(provider, dataTableName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sprocs, err := makeSqlCode(dataTable)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *rollback {
+			if err := writeRollback(conn, dataTable, sprocs); err != nil {
+				log.Fatal(err)
+			}
+		}
+		scripts = append(scripts, sprocs)
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		log.Fatalf("starting the transaction failed: %s", err)
+	}
+
+	created, err := executeScripts(tx, scripts)
+	if err != nil {
+		tx.Rollback()
+		log.Fatalf("%s, rolled back", err)
+	}
+
+	if *dryRun {
+		if err := tx.Rollback(); err != nil {
+			log.Fatal(err)
+		}
+		for _, sprocName := range created {
+			fmt.Printf("would create %s\n", sprocName)
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Fatalf("commit failed: %s", err)
+	}
+	for _, sprocName := range created {
+		fmt.Printf("created %s\n", sprocName)
+	}
+	fmt.Printf("deployed %d sprocs to %s on %s\n", len(created), *database, *server)
+}
+
+// executeScripts runs each batch of the scripts and returns the sprocs they created
+func executeScripts(tx *sql.Tx, scripts []string) ([]string, error) {
+	created := make([]string, 0)
+
+	for _, script := range scripts {
+		for _, batch := range splitBatches(script) {
+			if *debug {
+				log.Printf("executing:\n%s", batch)
+			}
+			if _, err := tx.Exec(batch); err != nil {
+				return nil, fmt.Errorf("executing batch failed: %s\n%s", err, batch)
+			}
+			if m := createProcPattern.FindStringSubmatch(batch); m != nil {
+				created = append(created, m[1])
+			}
+		}
+	}
+
+	return created, nil
+}
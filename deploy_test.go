@@ -0,0 +1,97 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// TestDeployRollback needs a SqlServer to deploy to, so it only runs when
+// TZSPROC_TEST_SERVER is set. TZSPROC_TEST_USER and TZSPROC_TEST_PASSWORD
+// override -user and -password. The sprocs go into tempdb and are always
+// rolled back, i.e. against the container in deploy.go:
+//
+//	TZSPROC_TEST_SERVER=localhost TZSPROC_TEST_USER=sa TZSPROC_TEST_PASSWORD=Passw0rd! go test -run Deploy
+func TestDeployRollback(t *testing.T) {
+	testServer := os.Getenv("TZSPROC_TEST_SERVER")
+	if testServer == "" {
+		t.Skip("TZSPROC_TEST_SERVER isn't set")
+	}
+	defer setFlag(server, testServer)()
+	defer setFlag(database, "tempdb")()
+	if testUser := os.Getenv("TZSPROC_TEST_USER"); testUser != "" {
+		defer setFlag(user, testUser)()
+	}
+	if testPassword := os.Getenv("TZSPROC_TEST_PASSWORD"); testPassword != "" {
+		defer setFlag(password, testPassword)()
+	}
+
+	scripts := make([]string, 0)
+	for _, dataTable := range []DataTable{employeeTable(), orderLineTable(), appLogTable()} {
+		sprocs, err := makeSqlCode(dataTable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scripts = append(scripts, sprocs)
+	}
+
+	conn, err := openConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the scripts have to run cleanly before the failure means anything
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := executeScripts(tx, scripts)
+	if err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	for _, sprocName := range created {
+		if !sprocExists(t, tx, sprocName) {
+			t.Errorf("%s wasn't created", sprocName)
+		}
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) == 0 {
+		t.Fatal("the scripts didn't create any sprocs")
+	}
+
+	// a failing batch after the sprocs rolls the lot back, the same as deploy does
+	tx, err = conn.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeScripts(tx, append(scripts, "raiserror('injected failure', 16, 1)\ngo\n")); err == nil {
+		tx.Rollback()
+		t.Fatal("executeScripts() should fail on the injected batch")
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sprocName := range created {
+		if sprocExists(t, conn, sprocName) {
+			t.Errorf("%s was left behind after the rollback", sprocName)
+		}
+	}
+}
+
+// sprocExists looks the sproc up through the transaction or connection
+func sprocExists(t *testing.T, db interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, sprocName string) bool {
+	t.Helper()
+
+	var id sql.NullInt64
+	if err := db.QueryRow("select object_id(?, 'P')", sprocName).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	return id.Valid
+}
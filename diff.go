@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// diffMode is set by the diff command, the generated code is compared to the
+// existing files instead of being written
+var diffMode = false
+
+// differences counts the files that don't match what we'd generate
+var differences = 0
+
+// the unchanged lines shown around each change
+const diffContext = 3
+
+// diffLine is one line of the edit script, kind is ' ', '-' or '+'
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// diffOutput prints a unified diff of the existing file against the generated code
+func diffOutput(fileName string, content string) error {
+	fromName := fileName
+	existing, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		fromName = "/dev/null"
+	} else if err != nil {
+		return err
+	}
+
+	if string(existing) == content {
+		return nil
+	}
+
+	differences++
+	fmt.Print(unifiedDiff(fromName, fileName, string(existing), content))
+	return nil
+}
+
+// splitLines breaks the text into lines, keeping the line endings so a
+// missing newline at the end counts as a change
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines works out the edit script from a to b off the longest common subsequence
+func diffLines(a []string, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// hunkRange returns the @@ range of a hunk, start is the count of lines before it
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// unifiedDiff returns the changes from a to b in unified diff format
+func unifiedDiff(fromName string, toName string, a string, b string) string {
+	var buffer bytes.Buffer
+
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	buffer.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	aLine, bLine := 0, 0 // the lines of a and b before lines[start]
+	for start := 0; start < len(lines); {
+		first := start
+		for first < len(lines) && lines[first].kind == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		// keep going until the changes are far enough apart to be separate hunks
+		last := first
+		for k := first; k < len(lines) && k-last <= 2*diffContext; k++ {
+			if lines[k].kind != ' ' {
+				last = k
+			}
+		}
+
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to := last + diffContext + 1
+		if to > len(lines) {
+			to = len(lines)
+		}
+
+		// skip the unchanged lines before the hunk
+		aLine += from - start
+		bLine += from - start
+
+		aCount, bCount := 0, 0
+		for _, line := range lines[from:to] {
+			if line.kind != '+' {
+				aCount++
+			}
+			if line.kind != '-' {
+				bCount++
+			}
+		}
+
+		buffer.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount)))
+		for _, line := range lines[from:to] {
+			buffer.WriteByte(line.kind)
+			buffer.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				buffer.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		aLine += aCount
+		bLine += bCount
+		start = to
+	}
+
+	return buffer.String()
+}
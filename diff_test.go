@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected string
+	}{
+		{"a\nb\nc\n", "a\nb\nc\n", "--- old\n+++ new\n"},
+		{"a\nb\nc\n", "a\nx\nc\n", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{"", "a\n", "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n"},
+		{"a\n", "a", "--- old\n+++ new\n@@ -1,1 +1,1 @@\n-a\n+a\n\\ No newline at end of file\n"},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+	}
+
+	for _, test := range tests {
+		if diff := unifiedDiff("old", "new", test.a, test.b); diff != test.expected {
+			t.Errorf("unifiedDiff(%q, %q) =\n%s\nexpected\n%s", test.a, test.b, diff, test.expected)
+		}
+	}
+}
+
+func TestDiffOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	existing := filepath.Join(dir, "Employee.cs")
+	if err := ioutil.WriteFile(existing, []byte("same\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(saved int) { differences = saved }(differences)
+	differences = 0
+
+	for _, test := range []struct {
+		fileName string
+		content  string
+	}{
+		{existing, "same\n"},
+		{existing, "changed\n"},
+		{filepath.Join(dir, "New.cs"), "new\n"},
+	} {
+		if err := diffOutput(test.fileName, test.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if differences != 2 {
+		t.Errorf("%d files differ, expected the changed and the new one", differences)
+	}
+	if data, _ := ioutil.ReadFile(existing); string(data) != "same\n" {
+		t.Error("diffOutput changed the existing file")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// createProcPattern finds the sproc name in a CREATE proc or CREATE OR ALTER PROCEDURE batch
+var createProcPattern = regexp.MustCompile(`(?i)\bcreate\s+(?:or\s+alter\s+)?proc(?:edure)?\s+([^\s(]+)`)
+
+// generatedSproc is one of the sprocs in the generated script
+type generatedSproc struct {
+	name       string
+	definition string
+}
+
+// drift compares the sprocs we'd generate with the ones deployed on -server/-database.
+// The tables can still come from -schema-file or -ddl, only the sprocs are read from the server.
+func drift() {
+	provider, err := newSchemaProvider()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer provider.Close()
+
+	dataTableNames, unmatched, err := findDataTables(provider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(unmatched) > 0 {
+		log.Fatalf("no tables matched %s", strings.Join(unmatched, ", "))
+	}
+	if len(dataTableNames) == 0 {
+		log.Fatal("no tables matched; use -table or -all")
+	}
+
+	conn, err := openConnection()
+	if err != nil {
+		log.Fatalf("Open connection failed: %s", err)
+	}
+	defer conn.Close()
+
+	checked := 0
+	drifted := 0
+	for _, dataTableName := range dataTableNames {
+		dataTable, err := loadDataTable(provider, dataTableName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		sprocs, err := makeSqlCode(dataTable)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, sproc := range findSprocs(sprocs) {
+			deployed, found, err := loadSprocDefinition(conn, sproc.name)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			checked++
+			switch {
+			case !found:
+				drifted++
+				fmt.Printf("missing   %s\n", sproc.name)
+			case normalizeSql(deployed) == normalizeSql(sproc.definition):
+				fmt.Printf("identical %s\n", sproc.name)
+			default:
+				drifted++
+				fmt.Printf("different %s\n", sproc.name)
+				fmt.Print(unifiedDiff("deployed "+sproc.name, "generated "+sproc.name,
+					normalizeSqlLines(deployed), normalizeSqlLines(sproc.definition)))
+			}
+		}
+	}
+
+	fmt.Printf("%d of %d sprocs drifted\n", drifted, checked)
+	if drifted > 0 {
+		os.Exit(1)
+	}
+}
+
+// splitBatches breaks a script into the batches between the go separators,
+// leaving out the empty ones
+func splitBatches(script string) []string {
+	batches := make([]string, 0)
+
+	var batch bytes.Buffer
+	for _, line := range splitLines(script) {
+		if strings.EqualFold(strings.TrimSpace(line), "go") {
+			if strings.TrimSpace(batch.String()) != "" {
+				batches = append(batches, batch.String())
+			}
+			batch.Reset()
+			continue
+		}
+		batch.WriteString(line)
+	}
+	if strings.TrimSpace(batch.String()) != "" {
+		batches = append(batches, batch.String())
+	}
+
+	return batches
+}
+
+// findSprocs returns the sprocs a generated script creates
+func findSprocs(script string) []generatedSproc {
+	sprocs := make([]generatedSproc, 0)
+	for _, batch := range splitBatches(script) {
+		if m := createProcPattern.FindStringSubmatch(batch); m != nil {
+			sprocs = append(sprocs, generatedSproc{name: m[1], definition: batch})
+		}
+	}
+	return sprocs
+}
+
+// loadSprocDefinition returns the text of a deployed sproc, found is false if it isn't there
+func loadSprocDefinition(conn *sql.DB, sprocName string) (definition string, found bool, err error) {
+	query := `select m.definition
+	from sys.sql_modules m join sys.objects o
+		on o.object_id = m.object_id
+	where o.type = 'P'
+	and o.object_id = object_id(?)`
+
+	err = conn.QueryRow(query, sprocName).Scan(&definition)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("loading %s failed: %s", sprocName, err)
+	}
+	return definition, true, nil
+}
+
+// normalizeSql squashes the whitespace so only real changes count as drift.
+// How the sproc was created doesn't matter either, so that's made the same.
+func normalizeSql(text string) string {
+	text = createProcPattern.ReplaceAllString(trimBeforeCreate(text), "CREATE proc $1")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// trimBeforeCreate drops whatever comes before the CREATE in a sproc's batch. With
+// create-or-alter the section banner is in the same batch, and SqlServer keeps it
+// as part of the definition, but with drop-create it goes with the drop.
+func trimBeforeCreate(text string) string {
+	if loc := createProcPattern.FindStringIndex(text); loc != nil {
+		return text[loc[0]:]
+	}
+	return text
+}
+
+// normalizeSqlLines squashes the whitespace on each line and drops the blank
+// ones, so the diff only shows the lines that matter
+func normalizeSqlLines(text string) string {
+	var buffer bytes.Buffer
+	for _, line := range strings.Split(strings.Replace(trimBeforeCreate(text), "\r\n", "\n", -1), "\n") {
+		if line = normalizeSql(line); line != "" {
+			buffer.WriteString(line + "\n")
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitBatches(t *testing.T) {
+	script := "use Internal\ngo\n\nCREATE proc a\nAS\nselect 1\nGO\ngo\n  Go  \ncreate proc b as select 2\n"
+
+	expected := []string{"use Internal\n", "\nCREATE proc a\nAS\nselect 1\n", "create proc b as select 2\n"}
+	if batches := splitBatches(script); !reflect.DeepEqual(batches, expected) {
+		t.Errorf("splitBatches() = %q, expected %q", batches, expected)
+	}
+}
+
+func TestFindSprocs(t *testing.T) {
+	script := "use Internal\ngo\nif exists (select name from sysobjects where name = 'stp_a')\n\tdrop proc stp_a\ngo\nCREATE proc stp_a \nAS\nselect 1\ngo\nCREATE OR ALTER PROCEDURE stp_b\nAS\nselect 2\ngo\n"
+
+	sprocs := findSprocs(script)
+	names := make([]string, 0)
+	for _, sproc := range sprocs {
+		names = append(names, sproc.name)
+	}
+	if expected := []string{"stp_a", "stp_b"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("findSprocs() = %q, expected %q", names, expected)
+	}
+}
+
+func TestNormalizeSql(t *testing.T) {
+	generated := "CREATE proc stp_a \n\t@Id int \nAS\nselect 1\n"
+	deployed := "CREATE proc stp_a\r\n    @Id int\r\n\r\nAS\r\nselect 1"
+
+	if normalizeSql(generated) != normalizeSql(deployed) {
+		t.Errorf("whitespace counted as drift: %q and %q", normalizeSql(generated), normalizeSql(deployed))
+	}
+	if normalizeSql(generated) == normalizeSql("CREATE proc stp_a @Id bigint AS select 1") {
+		t.Error("a changed parameter type didn't count as drift")
+	}
+	if normalizeSql("CREATE OR ALTER PROCEDURE stp_a @Id int AS select 1") != normalizeSql(deployed) {
+		t.Error("create-or-alter counted as drift")
+	}
+	if lines := normalizeSqlLines(deployed); lines != "CREATE proc stp_a\n@Id int\nAS\nselect 1\n" {
+		t.Errorf("normalizeSqlLines() = %q", lines)
+	}
+}
+
+// TestNormalizeSqlStyles checks a sproc generated with either -sql-style doesn't count as drift
+func TestNormalizeSqlStyles(t *testing.T) {
+	defer func(saved string) { *sqlStyle = saved }(*sqlStyle)
+
+	dataTable := employeeTable()
+	setMemberNames(&dataTable)
+
+	definitions := make(map[string]map[string]string)
+	for _, style := range []string{"drop-create", "create-or-alter"} {
+		*sqlStyle = style
+		sprocs, err := makeSqlCode(dataTable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		definitions[style] = make(map[string]string)
+		for _, sproc := range findSprocs(sprocs) {
+			definitions[style][sproc.name] = sproc.definition
+		}
+	}
+
+	if len(definitions["drop-create"]) != len(definitions["create-or-alter"]) {
+		t.Fatalf("the styles create different sprocs: %d and %d", len(definitions["drop-create"]), len(definitions["create-or-alter"]))
+	}
+	for name, definition := range definitions["drop-create"] {
+		other := definitions["create-or-alter"][name]
+		if normalizeSql(definition) != normalizeSql(other) {
+			t.Errorf("%s differs between the styles\n%s", name, unifiedDiff("drop-create", "create-or-alter", normalizeSqlLines(definition), normalizeSqlLines(other)))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var grant = newStringListFlag("grant", "grant EXECUTE on the generated sprocs to this role or user, can be repeated or comma-separated")
+
+// stringList is a flag that can be given more than once
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*list = append(*list, item)
+		}
+	}
+	return nil
+}
+
+// newStringListFlag defines a repeatable flag, like flag.String does for a single one
+func newStringListFlag(name string, usage string) *stringList {
+	list := &stringList{}
+	flag.Var(list, name, usage)
+	return list
+}
+
+// sprocGrant is an EXECUTE permission on a sproc
+type sprocGrant struct {
+	Sproc   string
+	Grantee string
+}
+
+// Grants returns who gets EXECUTE on one of the table's sprocs, by its quoted name.
+// That's the -grant names and then anyone that already had it, because dropping
+// the sproc loses those.
+func (dataTable DataTable) Grants(sprocName string) []sprocGrant {
+	return makeGrants(sprocName, dataTable.grants[strings.ToLower(sprocName)])
+}
+
+// makeGrants combines the -grant names with the existing grantees, leaving out repeats
+func makeGrants(sprocName string, existing []string) []sprocGrant {
+	grants := make([]sprocGrant, 0)
+	seen := make(map[string]bool)
+
+	for _, grantee := range append(append([]string{}, *grant...), existing...) {
+		if !seen[strings.ToLower(grantee)] {
+			seen[strings.ToLower(grantee)] = true
+			grants = append(grants, sprocGrant{Sproc: sprocName, Grantee: grantee})
+		}
+	}
+	return grants
+}
+
+// loadGrants returns who has EXECUTE on each of the stp_ sprocs in the database, by
+// the quoted sproc name in lower case, i.e. [hr].[stp_employee_ins], because -table
+// doesn't have to match the table's case. DENY and REVOKE aren't carried over,
+// only the grants.
+func loadGrants(conn *sql.DB) (map[string][]string, error) {
+	query := `select quotename(s.name) + '.' + quotename(o.name), pr.name
+	from sys.database_permissions p join sys.objects o
+		on o.object_id = p.major_id
+		join sys.schemas s
+			on s.schema_id = o.schema_id
+		join sys.database_principals pr
+			on pr.principal_id = p.grantee_principal_id
+	where p.class = 1
+	and p.type = 'EX'
+	and p.state in ('G', 'W')
+	and o.type = 'P'
+	and o.name like 'stp[_]%'
+	order by s.name, o.name, pr.name`
+
+	rows, err := conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("loading grants failed: %s", err)
+	}
+	defer rows.Close()
+
+	grants := make(map[string][]string)
+	for rows.Next() {
+		var sprocName, grantee string
+		if err := rows.Scan(&sprocName, &grantee); err != nil {
+			return nil, fmt.Errorf("loading grants failed: %s", err)
+		}
+		sprocName = strings.ToLower(sprocName)
+		grants[sprocName] = append(grants[sprocName], grantee)
+	}
+
+	return grants, rows.Err()
+}
+
+// filterGrants keeps the grants on the table's sprocs, the ones named stp_<table>_ in
+// its schema. The sproc names are lower cased like loadGrants does.
+func filterGrants(grants map[string][]string, dataTable DataTable) map[string][]string {
+	prefix := strings.ToLower(strings.TrimSuffix(dataTable.QualifiedSprocName(""), "]"))
+
+	filtered := make(map[string][]string)
+	for sprocName, grantees := range grants {
+		if strings.HasPrefix(strings.ToLower(sprocName), prefix) {
+			filtered[strings.ToLower(sprocName)] = grantees
+		}
+	}
+	return filtered
+}
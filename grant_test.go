@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestGrants(t *testing.T) {
+	defer func(saved stringList) { *grant = saved }(*grant)
+	*grant = stringList{"app"}
+
+	deployed := map[string][]string{
+		"[dbo].[stp_Employee_ins]":   {`DOMAIN\AppUsers`, "App"},
+		"[dbo].[stp_EmployeeIT_ins]": {"someone"},
+		"[hr].[stp_Employee_ins]":    {"someone"},
+		"[dbo].[stp_Other_ins]":      {"someone"},
+	}
+
+	// -table doesn't have to match the case the table was created with
+	dataTable := DataTable{schema: "dbo", name: "employee"}
+	dataTable.grants = filterGrants(deployed, dataTable)
+	if len(dataTable.grants) != 1 {
+		t.Fatalf("filterGrants() = %v, expected only the dbo.Employee sprocs", dataTable.grants)
+	}
+
+	// -grant comes first and the existing App is a repeat of it
+	grants := dataTable.Grants(dataTable.QualifiedSprocName("ins"))
+	if len(grants) != 2 || grants[0].Grantee != "app" || grants[1].Grantee != `DOMAIN\AppUsers` {
+		t.Fatalf("Grants() = %v, expected app and the existing DOMAIN\\AppUsers", grants)
+	}
+
+	code, err := executeTemplate("sql_grant.tmpl", grants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "GRANT EXECUTE ON [dbo].[stp_employee_ins] TO [app]\nGRANT EXECUTE ON [dbo].[stp_employee_ins] TO [DOMAIN\\AppUsers]\ngo\n"; code != expected {
+		t.Errorf("sql_grant.tmpl gave\n%s\nexpected\n%s", code, expected)
+	}
+
+	// nothing to grant, nothing generated
+	*grant = stringList{}
+	if code, err := executeTemplate("sql_grant.tmpl", dataTable.Grants(dataTable.QualifiedSprocName("del"))); err != nil || code != "" {
+		t.Errorf("sql_grant.tmpl gave %q, %v without any grants", code, err)
+	}
+}
+
+func TestStringListFlag(t *testing.T) {
+	list := stringList{}
+	for _, value := range []string{"app", " reports, ,audit "} {
+		if err := list.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if list.String() != "app,reports,audit" {
+		t.Errorf("the list is %q, expected app,reports,audit", list.String())
+	}
+}
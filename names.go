@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// csKeywords are the C# reserved words, a member with one of these names needs an @ in front
+var csKeywords = map[string]bool{
+	"abstract": true, "as": true, "base": true, "bool": true, "break": true, "byte": true,
+	"case": true, "catch": true, "char": true, "checked": true, "class": true, "const": true,
+	"continue": true, "decimal": true, "default": true, "delegate": true, "do": true, "double": true,
+	"else": true, "enum": true, "event": true, "explicit": true, "extern": true, "false": true,
+	"finally": true, "fixed": true, "float": true, "for": true, "foreach": true, "goto": true,
+	"if": true, "implicit": true, "in": true, "int": true, "interface": true, "internal": true,
+	"is": true, "lock": true, "long": true, "namespace": true, "new": true, "null": true,
+	"object": true, "operator": true, "out": true, "override": true, "params": true, "private": true,
+	"protected": true, "public": true, "readonly": true, "ref": true, "return": true, "sbyte": true,
+	"sealed": true, "short": true, "sizeof": true, "stackalloc": true, "static": true, "string": true,
+	"struct": true, "switch": true, "this": true, "throw": true, "true": true, "try": true,
+	"typeof": true, "uint": true, "ulong": true, "unchecked": true, "unsafe": true, "ushort": true,
+	"using": true, "virtual": true, "void": true, "volatile": true, "while": true,
+}
+
+// classMemberNames are taken by the generated class, its methods and the locals
+// in them, or by the variables in the sprocs, so a column can't use them as is
+var classMemberNames = []string{
+	"Save", "BulkSave", "Insert", "Update", "Delete", "Load", "LoadPage", "LoadAll", "Search", "loadFromRow", "addParameters", "getConnection",
+	"row", "cmd", "conn", "dt", "iReturn", "bResult", "isUpdate", "version", "versions",
+}
+
+// sanitizeIdentifier turns a SQL name into something C# accepts, anything that
+// isn't a letter, digit or underscore becomes an underscore
+func sanitizeIdentifier(name string) string {
+	var buffer bytes.Buffer
+
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			buffer.WriteRune(r)
+		} else {
+			buffer.WriteRune('_')
+		}
+	}
+
+	identifier := buffer.String()
+	if identifier == "" || unicode.IsDigit([]rune(identifier)[0]) {
+		identifier = "_" + identifier
+	}
+	return identifier
+}
+
+// escapeKeyword puts an @ in front of C# reserved words, @class is fine as a member name
+func escapeKeyword(name string) string {
+	if csKeywords[name] {
+		return "@" + name
+	}
+	return name
+}
+
+// getClassName returns the C# class name for the table
+func getClassName(dataTable DataTable) string {
+	return escapeKeyword(sanitizeIdentifier(dataTable.name))
+}
+
+// setMemberNames works out the C# member for each column. Besides the illegal
+// characters, a member can't have the class's name or the name of one of its
+// methods, those get Value on the end. Columns that are fine as they are keep
+// their names, and the ones that had to change get a number if they collide.
+func setMemberNames(dataTable *DataTable) {
+	className := sanitizeIdentifier(dataTable.name)
+
+	// the member names are the sproc parameter names too, and T-SQL doesn't care
+	// about case, so the maps are kept in lower case
+	reserved := make(map[string]bool)
+	for _, name := range classMemberNames {
+		reserved[strings.ToLower(name)] = true
+	}
+	reserved[strings.ToLower(className)] = true
+
+	taken := make(map[string]bool)
+	for i, column := range dataTable.columns {
+		name := column.column_name
+		if key := strings.ToLower(name); name == sanitizeIdentifier(name) && !reserved[key] && !taken[key] {
+			dataTable.columns[i].member_name = name
+			taken[key] = true
+		}
+	}
+
+	for i, column := range dataTable.columns {
+		if column.member_name != "" {
+			continue
+		}
+		name := sanitizeIdentifier(column.column_name)
+		if reserved[strings.ToLower(name)] {
+			name += "Value"
+		}
+		unique := name
+		for n := 2; taken[strings.ToLower(unique)] || reserved[strings.ToLower(unique)]; n++ {
+			unique = fmt.Sprintf("%s%d", name, n)
+		}
+		dataTable.columns[i].member_name = unique
+		taken[strings.ToLower(unique)] = true
+	}
+}
+
+// getMemberName returns the column's member name without the keyword escaping,
+// which is what the sproc parameter is called as well
+func getMemberName(column Column) string {
+	if column.member_name == "" {
+		return sanitizeIdentifier(column.column_name)
+	}
+	return column.member_name
+}
+
+// getClassMemberName returns the C# property for the column, i.e. HourlyWage or @class
+func getClassMemberName(column Column) string {
+	return escapeKeyword(getMemberName(column))
+}
+
+// getParameterName returns the sproc parameter for the column, i.e. @HourlyWage
+func getParameterName(column Column) string {
+	return "@" + getMemberName(column)
+}
+
+// sqlString quotes the text as a T-SQL string literal
+func sqlString(text string) string {
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
+
+// csString quotes the text as a C# string literal
+func csString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetMemberNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  []string
+		expected []string
+	}{
+		{"Employee", []string{"EmployeeId", "Name"}, []string{"EmployeeId", "Name"}},
+		{"Order", []string{"Order", "Save", "row"}, []string{"OrderValue", "SaveValue", "rowValue"}},
+		{"Order", []string{"First Name", "First_Name"}, []string{"First_Name2", "First_Name"}},
+		// the members are the sproc parameters too, and those ignore case
+		{"Order", []string{"Order Date", "order_date"}, []string{"Order_Date2", "order_date"}},
+		{"Order", []string{"order", "SAVE"}, []string{"orderValue", "SAVEValue"}},
+		{"Order", []string{"2nd", "class", "a-b", "a+b"}, []string{"_2nd", "class", "a_b", "a_b2"}},
+		{"Account", []string{"Balance", "version", "versions"}, []string{"Balance", "versionValue", "versionsValue"}},
+		// the sproc variables don't care about case either
+		{"Account", []string{"Versions", "VERSION"}, []string{"VersionsValue", "VERSIONValue"}},
+	}
+
+	for _, test := range tests {
+		dataTable := DataTable{name: test.name}
+		for _, columnName := range test.columns {
+			dataTable.columns = append(dataTable.columns, Column{column_name: columnName})
+		}
+		setMemberNames(&dataTable)
+
+		memberNames := make([]string, 0)
+		for _, column := range dataTable.columns {
+			memberNames = append(memberNames, column.member_name)
+		}
+		if !reflect.DeepEqual(memberNames, test.expected) {
+			t.Errorf("%s %q got member names %q, expected %q", test.name, test.columns, memberNames, test.expected)
+		}
+	}
+}
+
+func TestGetClassMemberName(t *testing.T) {
+	column := Column{column_name: "class", member_name: "class"}
+	if name := getClassMemberName(column); name != "@class" {
+		t.Errorf("getClassMemberName(class) = %s, expected @class", name)
+	}
+	if name := getParameterName(column); name != "@class" {
+		t.Errorf("getParameterName(class) = %s, expected @class", name)
+	}
+}
+
+func TestSqlString(t *testing.T) {
+	if s := sqlString("O'Brien"); s != "'O''Brien'" {
+		t.Errorf("sqlString(O'Brien) = %s", s)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var out = flag.String("out", ".", "the directory the generated files are written to")
+var sqlOut = flag.String("sql-out", "", "the directory for the sproc scripts, defaults to -out")
+var csOut = flag.String("cs-out", "", "the directory for the C# classes, defaults to -out")
+var sqlLayout = flag.String("sql-layout", "CREATE_{name}.sql", "the sproc script path under -sql-out, {database}, {schema}, {table} and {name} are filled in")
+var csLayout = flag.String("cs-layout", "{name}.cs", "the C# class path under -cs-out, {database}, {schema}, {table} and {name} are filled in")
+var dryRun = flag.Bool("dry-run", false, "list the files that would be created or overwritten without writing them")
+var toStdout = flag.Bool("stdout", false, "write the generated code to stdout instead of the files")
+
+// expandLayout fills in the placeholders of a layout pattern for the table.
+// {name} is the table name, with the schema in front if it isn't dbo, so
+// hr.Employee and dbo.Employee don't write over each other.
+func expandLayout(layout string, dataTable DataTable) string {
+	name := dataTable.name
+	if !strings.EqualFold(getSchemaName(dataTable), defaultSchema) {
+		name = getDataTableFullName(dataTable)
+	}
+
+	return strings.NewReplacer(
+		"{database}", *database,
+		"{schema}", getSchemaName(dataTable),
+		"{table}", dataTable.name,
+		"{name}", name,
+	).Replace(layout)
+}
+
+// getOutputDir returns dir, or -out if it wasn't given
+func getOutputDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+	return *out
+}
+
+// getSqlFileName returns where the table's sproc script goes, i.e. ./CREATE_Employee.sql
+func getSqlFileName(dataTable DataTable) string {
+	return filepath.Join(getOutputDir(*sqlOut), filepath.FromSlash(expandLayout(*sqlLayout, dataTable)))
+}
+
+// getClassFileName returns where the table's C# class goes, i.e. ./Employee.cs
+func getClassFileName(dataTable DataTable) string {
+	return filepath.Join(getOutputDir(*csOut), filepath.FromSlash(expandLayout(*csLayout, dataTable)))
+}
+
+// writeOutput saves the generated code, making the directories it needs on the way.
+// With -stdout the code is streamed instead, the diff command compares it to what's
+// there, and -dry-run only says what would happen.
+func writeOutput(fileName string, content string) error {
+	if *toStdout {
+		_, err := os.Stdout.WriteString(content)
+		return err
+	}
+	if diffMode {
+		return diffOutput(fileName, content)
+	}
+	if *dryRun {
+		fmt.Printf("%s %s\n", getOutputAction(fileName, content), fileName)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return fmt.Errorf("creating the directory for %s failed: %s", fileName, err)
+	}
+	if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing %s failed: %s", fileName, err)
+	}
+	return nil
+}
+
+// getOutputAction tells us what writing the file would do: create, overwrite or unchanged
+func getOutputAction(fileName string, content string) string {
+	existing, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return "create"
+	}
+	if string(existing) == content {
+		return "unchanged"
+	}
+	return "overwrite"
+}
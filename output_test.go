@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setFlag changes a string flag for the rest of the test, returning the function that puts it back
+func setFlag(value *string, newValue string) func() {
+	saved := *value
+	*value = newValue
+	return func() { *value = saved }
+}
+
+func TestOutputFileNames(t *testing.T) {
+	defer setFlag(database, "Payroll")()
+	defer setFlag(out, "gen")()
+	defer setFlag(sqlOut, "")()
+	defer setFlag(csOut, "")()
+	defer setFlag(sqlLayout, "CREATE_{name}.sql")()
+	defer setFlag(csLayout, "{name}.cs")()
+
+	dataTable := employeeTable()
+
+	if fileName := getSqlFileName(dataTable); fileName != filepath.Join("gen", "CREATE_Employee.sql") {
+		t.Errorf("the sproc script goes to %s by default", fileName)
+	}
+	if fileName := getClassFileName(dataTable); fileName != filepath.Join("gen", "Employee.cs") {
+		t.Errorf("the class goes to %s by default", fileName)
+	}
+
+	// outside dbo the schema goes in the name, so hr.Employee doesn't overwrite dbo.Employee
+	hrEmployee := DataTable{schema: "hr", name: "Employee"}
+	if fileName := getSqlFileName(hrEmployee); fileName != filepath.Join("gen", "CREATE_hr.Employee.sql") {
+		t.Errorf("the hr.Employee sproc script goes to %s", fileName)
+	}
+
+	*sqlOut = "sql"
+	*csLayout = "{database}/{schema}/{table}.cs"
+	dataTable = orderLineTable()
+	if fileName := getSqlFileName(dataTable); fileName != filepath.Join("sql", "CREATE_sales.OrderLine.sql") {
+		t.Errorf("-sql-out should override -out, got %s", fileName)
+	}
+	if fileName := getClassFileName(dataTable); fileName != filepath.Join("gen", "Payroll", "sales", "OrderLine.cs") {
+		t.Errorf("the -cs-layout placeholders gave %s", fileName)
+	}
+}
+
+func TestProcessDataTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer setFlag(out, dir)()
+	defer setFlag(sqlOut, "")()
+	defer setFlag(csOut, "")()
+	defer setFlag(sqlLayout, "sql/CREATE_{table}.sql")()
+	defer setFlag(csLayout, "cs/{table}.cs")()
+
+	if err := processDataTable(employeeTable(), nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, fileName := range []string{"sql/CREATE_Employee.sql", "cs/Employee.cs"} {
+		if _, err := os.Stat(filepath.Join(dir, fileName)); err != nil {
+			t.Errorf("%s wasn't written: %s", fileName, err)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	existing := filepath.Join(dir, "Existing.cs")
+	if err := ioutil.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		fileName string
+		content  string
+		expected string
+	}{
+		{filepath.Join(dir, "New.cs"), "new", "create"},
+		{existing, "new", "overwrite"},
+		{existing, "old", "unchanged"},
+	}
+	for _, test := range tests {
+		if action := getOutputAction(test.fileName, test.content); action != test.expected {
+			t.Errorf("writing %q to %s would %s, expected %s", test.content, filepath.Base(test.fileName), action, test.expected)
+		}
+	}
+
+	defer func(saved bool) { *dryRun = saved }(*dryRun)
+	*dryRun = true
+
+	if err := writeOutput(filepath.Join(dir, "sub", "New.cs"), "new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutput(existing, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Error("-dry-run made the output directory")
+	}
+	if data, _ := ioutil.ReadFile(existing); string(data) != "old" {
+		t.Errorf("-dry-run overwrote %s", existing)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/denisenkom/go-mssqldb"
+)
+
+// SchemaProvider is where the table details come from. The generators only
+// ever see the DataTable, so they don't care if it came from a live server,
+// a snapshot file or somewhere else.
+type SchemaProvider interface {
+	// ListDataTables returns the schema.table names of all the tables the provider knows about
+	ListDataTables() ([]string, error)
+	// LoadDataTable returns the table and its columns, in column_id order.
+	// The name can have a schema in front, it's dbo if it doesn't.
+	LoadDataTable(dataTableName string) (DataTable, error)
+	Close() error
+}
+
+// newSchemaProvider picks the provider from the command line flags
+func newSchemaProvider() (SchemaProvider, error) {
+	if *schemaFile != "" {
+		return newSnapshotProvider(*schemaFile)
+	}
+	if *ddl != "" {
+		return newDdlProvider(strings.Split(*ddl, ","))
+	}
+	return newSqlServerProvider()
+}
+
+// loadDataTable gets the table from the provider and works out the names the
+// generated code uses for its columns
+func loadDataTable(provider SchemaProvider, dataTableName string) (DataTable, error) {
+	dataTable, err := provider.LoadDataTable(dataTableName)
+	if err != nil {
+		return dataTable, err
+	}
+	setMemberNames(&dataTable)
+	return dataTable, nil
+}
+
+// getConnectionString returns connection string for the SqlServer
+func getConnectionString() string {
+	connString := fmt.Sprintf("server=%s;port=%d;database=%s;user=%s;password=%s", *server, *port, *database, *user, *password)
+	return connString
+}
+
+// openConnection opens the SqlServer connection
+func openConnection() (*sql.DB, error) {
+	return sql.Open("mssql", getConnectionString())
+}
+
+// sqlServerProvider reads the table details from sys.objects / sys.columns
+type sqlServerProvider struct {
+	conn   *sql.DB
+	grants map[string][]string // every EXECUTE grant on a stp_ sproc, loaded with the first table
+}
+
+func newSqlServerProvider() (*sqlServerProvider, error) {
+	conn, err := openConnection()
+	if err != nil {
+		return nil, fmt.Errorf("Open connection failed: %s", err)
+	}
+	return &sqlServerProvider{conn: conn}, nil
+}
+
+func (provider *sqlServerProvider) Close() error {
+	return provider.conn.Close()
+}
+
+// ListDataTables returns the names of all the user tables in the database
+func (provider *sqlServerProvider) ListDataTables() ([]string, error) {
+	rows, err := provider.conn.Query(`select s.name + '.' + o.name
+	from sys.objects o join sys.schemas s
+		on s.schema_id = o.schema_id
+	where o.type = 'u'
+	order by s.name, o.name`)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables failed: %s", err)
+	}
+	defer rows.Close()
+
+	dataTableNames := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("listing tables failed: %s", err)
+		}
+		dataTableNames = append(dataTableNames, name)
+	}
+
+	return dataTableNames, rows.Err()
+}
+
+// LoadDataTable grabs the dataTable and column details from the database
+func (provider *sqlServerProvider) LoadDataTable(dataTableName string) (DataTable, error) {
+	dataTable := DataTable{}
+	dataTable.schema, dataTable.name = splitTableName(dataTableName)
+
+	// the primary key comes from the index behind it, key_ordinal gives the column order
+	sql := `select a.name as dataTable_name, b.name as column_name, c.name as data_type,
+		b.max_length, b.precision, b.scale, b.column_id,  b.is_identity, b.is_computed, b.is_nullable,
+		isnull(k.key_ordinal, 0) as key_ordinal
+	from sys.objects a join sys.columns b
+		on b.object_id = a.object_id
+		join sys.schemas s
+			on s.schema_id = a.schema_id
+		join sys.types c
+			on c.user_type_id = b.user_type_id
+		left join (select ic.object_id, ic.column_id, ic.key_ordinal
+			from sys.indexes i join sys.index_columns ic
+				on ic.object_id = i.object_id and ic.index_id = i.index_id
+			where i.is_primary_key = 1) k
+			on k.object_id = b.object_id and k.column_id = b.column_id
+	where a.type = 'u'
+	and s.name = ?
+	and a.name = ?
+	order by a.name, b.column_id`
+
+	stmt, err := provider.conn.Prepare(sql)
+	if err != nil {
+		return dataTable, fmt.Errorf("prepare failed: %s", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.Query(dataTable.schema, dataTable.name)
+	if err != nil {
+		return dataTable, fmt.Errorf("query failed: %s", err)
+	}
+	defer rows.Close()
+
+	var column Column
+
+	for rows.Next() {
+		err = rows.Scan(&column.dataTable_name, &column.column_name, &column.data_type, &column.max_length, &column.precision,
+			&column.scale, &column.column_id, &column.is_identity, &column.is_computed, &column.is_nullable, &column.key_ordinal)
+		if err != nil {
+			return dataTable, fmt.Errorf("scan failed: %s", err)
+		}
+		dataTable.columns = append(dataTable.columns, column)
+
+	}
+
+	if len(dataTable.columns) == 0 {
+		return dataTable, fmt.Errorf("table %s not found", dataTableName)
+	}
+
+	if provider.grants == nil {
+		if provider.grants, err = loadGrants(provider.conn); err != nil {
+			return dataTable, err
+		}
+	}
+	dataTable.grants = filterGrants(provider.grants, dataTable)
+
+	return dataTable, nil
+
+}
+
+// memoryProvider serves tables that are already built, handy for tests
+type memoryProvider struct {
+	dataTables []DataTable
+}
+
+func newMemoryProvider(dataTables ...DataTable) *memoryProvider {
+	return &memoryProvider{dataTables: dataTables}
+}
+
+func (provider *memoryProvider) Close() error {
+	return nil
+}
+
+func (provider *memoryProvider) ListDataTables() ([]string, error) {
+	dataTableNames := make([]string, 0, len(provider.dataTables))
+	for _, dataTable := range provider.dataTables {
+		dataTableNames = append(dataTableNames, getDataTableFullName(dataTable))
+	}
+	return dataTableNames, nil
+}
+
+func (provider *memoryProvider) LoadDataTable(dataTableName string) (DataTable, error) {
+	schema, name := splitTableName(dataTableName)
+	for _, dataTable := range provider.dataTables {
+		if strings.EqualFold(getSchemaName(dataTable), schema) && strings.EqualFold(dataTable.name, name) {
+			return dataTable, nil
+		}
+	}
+	return DataTable{}, fmt.Errorf("table %s not found", dataTableName)
+}
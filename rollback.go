@@ -0,0 +1,60 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"path/filepath"
+	"strings"
+)
+
+var rollback = flag.Bool("rollback", false, "save the deployed sprocs to a rollback script before they're replaced")
+var rollbackLayout = flag.String("rollback-layout", "ROLLBACK_{name}.sql", "the rollback script path under -sql-out, {database}, {schema}, {table} and {name} are filled in")
+
+// rollbackSproc is a sproc the way it's deployed now, the Definition is empty
+// if it isn't deployed yet
+type rollbackSproc struct {
+	Name       string
+	Definition string
+	Grants     []sprocGrant // put back after the sproc is recreated
+}
+
+// getRollbackFileName returns where the table's rollback script goes, i.e. ./ROLLBACK_Employee.sql
+func getRollbackFileName(dataTable DataTable) string {
+	return filepath.Join(getOutputDir(*sqlOut), filepath.FromSlash(expandLayout(*rollbackLayout, dataTable)))
+}
+
+// makeRollbackCode generates the script that puts back the deployed versions of
+// the sprocs in the generated script. Sprocs that weren't there before get dropped.
+func makeRollbackCode(conn *sql.DB, sprocs string) (string, error) {
+	deployed := make([]rollbackSproc, 0)
+
+	grants, err := loadGrants(conn)
+	if err != nil {
+		return "", err
+	}
+
+	for _, sproc := range findSprocs(sprocs) {
+		definition, _, err := loadSprocDefinition(conn, sproc.name)
+		if err != nil {
+			return "", err
+		}
+
+		// only what it had before, not the -grant names
+		deployedSproc := rollbackSproc{Name: sproc.name, Definition: strings.TrimSpace(definition)}
+		for _, grantee := range grants[strings.ToLower(sproc.name)] {
+			deployedSproc.Grants = append(deployedSproc.Grants, sprocGrant{Sproc: sproc.name, Grantee: grantee})
+		}
+		deployed = append(deployed, deployedSproc)
+	}
+
+	return executeTemplate("rollback.tmpl", deployed)
+}
+
+// writeRollback saves the rollback script for the table
+func writeRollback(conn *sql.DB, dataTable DataTable, sprocs string) error {
+	code, err := makeRollbackCode(conn, sprocs)
+	if err != nil {
+		return err
+	}
+	return writeOutput(getRollbackFileName(dataTable), code)
+}
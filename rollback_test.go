@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRollbackTemplate(t *testing.T) {
+	deployed := []rollbackSproc{
+		{Name: "stp_Employee_ins", Definition: "CREATE proc stp_Employee_ins AS select 1"},
+		{Name: "stp_Employee_upd"},
+	}
+
+	code, err := executeTemplate("rollback.tmpl", deployed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(code, "CREATE proc stp_Employee_ins AS select 1\ngo\n") {
+		t.Errorf("the deployed stp_Employee_ins isn't put back:\n%s", code)
+	}
+	if strings.Count(code, "drop proc stp_Employee_upd") != 1 || strings.Contains(code, "CREATE proc stp_Employee_upd") {
+		t.Errorf("stp_Employee_upd wasn't deployed before, so it should only be dropped:\n%s", code)
+	}
+	if n := len(findSprocs(code)); n != 1 {
+		t.Errorf("the rollback script creates %d sprocs, expected 1:\n%s", n, code)
+	}
+}
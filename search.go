@@ -0,0 +1,224 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var search = newTableColumnsFlag("search", "the columns stp_<table>_search filters on as table=col1,col2, can be repeated. Tables not listed search every column that can be, table= leaves the search out")
+
+// tableColumns is a flag that lists columns per table, i.e. -search Employee=Name,HireDate
+type tableColumns map[string][]string
+
+func (t tableColumns) String() string {
+	items := make([]string, 0, len(t))
+	for name, columnNames := range t {
+		items = append(items, name+"="+strings.Join(columnNames, ","))
+	}
+	sort.Strings(items)
+	return strings.Join(items, " ")
+}
+
+func (t tableColumns) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("expected table=col1,col2 but found %q", value)
+	}
+
+	columnNames := make([]string, 0)
+	for _, columnName := range strings.Split(parts[1], ",") {
+		if columnName = strings.TrimSpace(columnName); columnName != "" {
+			columnNames = append(columnNames, columnName)
+		}
+	}
+	t[strings.ToLower(strings.TrimSpace(parts[0]))] = columnNames
+	return nil
+}
+
+// newTableColumnsFlag defines a repeatable table=col1,col2 flag
+func newTableColumnsFlag(name string, usage string) tableColumns {
+	t := make(tableColumns)
+	flag.Var(t, name, usage)
+	return t
+}
+
+// lookup returns the columns listed for the table, by schema.table or just the
+// table name. ok is false if the table isn't listed at all.
+func (t tableColumns) lookup(dataTable DataTable) (columnNames []string, ok bool) {
+	if columnNames, ok = t[strings.ToLower(getDataTableFullName(dataTable))]; ok {
+		return columnNames, ok
+	}
+	columnNames, ok = t[strings.ToLower(dataTable.name)]
+	return columnNames, ok
+}
+
+// searchKind says how the search sproc filters on a column
+type searchKind int
+
+const (
+	noSearch    searchKind = iota
+	equalSearch            // [Active] = @Active
+	likeSearch             // [Name] LIKE @Name, the caller adds the wildcards
+	rangeSearch            // [HireDate] between @HireDateFrom and @HireDateTo, either can be left out
+)
+
+// getSearchKind returns how a column can be searched, blobs and the CLR types can't
+func getSearchKind(column Column) searchKind {
+	switch column.data_type {
+	case "char", "varchar", "nchar", "nvarchar":
+		return likeSearch
+	case "date", "smalldatetime", "datetime", "datetime2", "datetimeoffset", "time":
+		return rangeSearch
+	case "text", "ntext", "image", "xml", "binary", "varbinary", "timestamp", "rowversion",
+		"sql_variant", "hierarchyid", "geography", "geometry":
+		return noSearch
+	}
+	if _, ok := sqlTypes[column.data_type]; !ok {
+		return noSearch
+	}
+	return equalSearch
+}
+
+// searchParameter is one of the optional parameters of the search sproc
+type searchParameter struct {
+	Column   Column // the column it filters on, its member_name is the parameter's
+	Operator string // how the column is compared to the parameter, i.e. LIKE or >=
+	csName   string // the Search() argument, set by SearchParameters
+}
+
+// Name returns the sproc parameter, i.e. @HireDateFrom
+func (parameter searchParameter) Name() string { return getParameterName(parameter.Column) }
+
+// Declaration returns the parameter declaration, they're all optional
+func (parameter searchParameter) Declaration() string { return getMetaData(parameter.Column) }
+
+// CsType returns the C# type of the Search() argument, nullable so it can be left out
+func (parameter searchParameter) CsType() string { return getClassDataType(parameter.Column) }
+
+// CsName returns the Search() argument, i.e. hireDateFrom
+func (parameter searchParameter) CsName() string { return parameter.csName }
+
+// newSearchParameter makes the parameter for the column, name is the sproc
+// parameter without the @
+func newSearchParameter(column Column, name string, operator string) searchParameter {
+	column.member_name = name
+	column.is_nullable = true
+	return searchParameter{Column: column, Operator: operator}
+}
+
+// getLikePatternColumn returns the column as the LIKE parameter declares it. A
+// pattern is longer than the values it matches, so it gets room for a wildcard
+// or escape per character and a % at each end. A char pattern would be blank
+// padded to its length, which LIKE doesn't ignore, so it's a varchar.
+func getLikePatternColumn(column Column) Column {
+	switch column.data_type {
+	case "char":
+		column.data_type = "varchar"
+	case "nchar":
+		column.data_type = "nvarchar"
+	}
+
+	if column.max_length != -1 {
+		charSize := 1
+		if column.data_type == "nvarchar" {
+			charSize = 2
+		}
+		// max_length is in bytes, and past 8000 it has to be MAX
+		if column.max_length = 2*column.max_length + 2*charSize; column.max_length > 8000 {
+			column.max_length = -1
+		}
+	}
+	return column
+}
+
+// uniqueName returns name, or name with a number on the end if it's taken,
+// and marks it as taken. SqlServer ignores case, so taken is lower case.
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for n := 2; taken[strings.ToLower(unique)]; n++ {
+		unique = fmt.Sprintf("%s%d", name, n)
+	}
+	taken[strings.ToLower(unique)] = true
+	return unique
+}
+
+// SearchParameters returns the search sproc's parameters, from -search if the
+// table is listed there, otherwise every column that can be searched
+func (dataTable DataTable) SearchParameters() ([]searchParameter, error) {
+	columns := make([]Column, 0)
+
+	if columnNames, ok := search.lookup(dataTable); ok {
+		for _, columnName := range columnNames {
+			column, found := findColumn(dataTable, columnName)
+			if !found {
+				return nil, fmt.Errorf("-search: %s has no column %s", dataTable.name, columnName)
+			}
+			if getSearchKind(column) == noSearch {
+				return nil, fmt.Errorf("-search: %s.%s is a %s, which can't be searched", dataTable.name, columnName, column.data_type)
+			}
+			columns = append(columns, column)
+		}
+	} else {
+		for _, column := range dataTable.columns {
+			if getSearchKind(column) != noSearch {
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	// the range parameters can come out the same as another column, i.e. HireDate
+	// and HireDateFrom, those get a number. The rest keep the column's member name,
+	// which is unique already.
+	taken := make(map[string]bool)
+	for _, column := range columns {
+		if getSearchKind(column) != rangeSearch {
+			taken[strings.ToLower(getMemberName(column))] = true
+		}
+	}
+
+	parameters := make([]searchParameter, 0)
+	for _, column := range columns {
+		name := getMemberName(column)
+		switch getSearchKind(column) {
+		case likeSearch:
+			parameters = append(parameters, newSearchParameter(getLikePatternColumn(column), name, "LIKE"))
+		case rangeSearch:
+			parameters = append(parameters,
+				newSearchParameter(column, uniqueName(name+"From", taken), ">="),
+				newSearchParameter(column, uniqueName(name+"To", taken), "<="))
+		default:
+			parameters = append(parameters, newSearchParameter(column, name, "="))
+		}
+	}
+
+	// camelCase can make two arguments the same, i.e. Order_Date and OrderDate.
+	// The locals in Search() are taken as well.
+	locals := map[string]bool{"list": true, "conn": true, "cmd": true, "dt": true, "row": true, "record": true}
+	csNames := make(map[string]bool)
+	for i := range parameters {
+		name := camelCase(getMemberName(parameters[i].Column))
+		if locals[name] {
+			name += "Value"
+		}
+		// C# cares about case, so these aren't lower cased
+		unique := name
+		for n := 2; csNames[unique] || locals[unique]; n++ {
+			unique = fmt.Sprintf("%s%d", name, n)
+		}
+		csNames[unique] = true
+		parameters[i].csName = escapeKeyword(unique)
+	}
+	return parameters, nil
+}
+
+// findColumn returns the column with the given name, ignoring case like SqlServer does
+func findColumn(dataTable DataTable, columnName string) (Column, bool) {
+	for _, column := range dataTable.columns {
+		if strings.EqualFold(column.column_name, columnName) {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
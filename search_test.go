@@ -0,0 +1,146 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchParameters(t *testing.T) {
+	defer func(saved tableColumns) { search = saved }(search)
+	search = make(tableColumns)
+
+	dataTable := employeeTable()
+	setMemberNames(&dataTable)
+
+	describe := func(parameters []searchParameter) []string {
+		described := make([]string, 0)
+		for _, parameter := range parameters {
+			described = append(described, parameter.Column.column_name+" "+parameter.Operator+" "+parameter.Name())
+		}
+		return described
+	}
+
+	// every column that can be searched, the dates by range
+	parameters, err := dataTable.SearchParameters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"EmployeeId = @EmployeeId", "Name LIKE @Name", "HourlyWage = @HourlyWage",
+		"HireDate >= @HireDateFrom", "HireDate <= @HireDateTo", "FullName LIKE @FullName",
+	}
+	if described := describe(parameters); !reflect.DeepEqual(described, expected) {
+		t.Errorf("SearchParameters() = %q, expected %q", described, expected)
+	}
+	for _, parameter := range parameters {
+		if !parameter.Column.is_nullable {
+			t.Errorf("%s should be optional", parameter.Name())
+		}
+	}
+
+	// -search picks the columns, by schema.table or just the table
+	for _, value := range []string{"Employee=hiredate, Name", "dbo.Employee=Name"} {
+		search = make(tableColumns)
+		if err := search.Set(value); err != nil {
+			t.Fatal(err)
+		}
+		if parameters, err = dataTable.SearchParameters(); err != nil {
+			t.Fatal(err)
+		}
+		if len(parameters) == 0 || parameters[len(parameters)-1].Name() != "@Name" {
+			t.Errorf("-search %s gave %q", value, describe(parameters))
+		}
+	}
+
+	for _, value := range []string{"Employee=Nope", "Employee=EmployeeId,Nope"} {
+		search = make(tableColumns)
+		search.Set(value)
+		if _, err := dataTable.SearchParameters(); err == nil {
+			t.Errorf("-search %s should fail", value)
+		}
+	}
+
+	for _, value := range []string{"Employee", "=Name"} {
+		if err := make(tableColumns).Set(value); err == nil {
+			t.Errorf("-search %s should fail", value)
+		}
+	}
+}
+
+func TestSearchLikeDeclaration(t *testing.T) {
+	tests := []struct {
+		column   Column
+		expected string
+	}{
+		{Column{column_name: "Name", data_type: "nvarchar", max_length: 100}, "@Name nvarchar(102) = NULL "},
+		{Column{column_name: "Code", data_type: "char", max_length: 3}, "@Code varchar(8) = NULL "},
+		{Column{column_name: "Title", data_type: "nchar", max_length: 20}, "@Title nvarchar(22) = NULL "},
+		{Column{column_name: "Notes", data_type: "varchar", max_length: 5000}, "@Notes varchar(MAX) = NULL "},
+		{Column{column_name: "Body", data_type: "nvarchar", max_length: -1}, "@Body nvarchar(MAX) = NULL "},
+	}
+
+	for _, test := range tests {
+		dataTable := DataTable{schema: "dbo", name: "t", columns: []Column{test.column}}
+		parameters, err := dataTable.SearchParameters()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if declaration := parameters[0].Declaration(); declaration != test.expected {
+			t.Errorf("%s %s(%d) is declared %q, expected %q", test.column.column_name, test.column.data_type, test.column.max_length, declaration, test.expected)
+		}
+	}
+}
+
+func TestGetSearchKind(t *testing.T) {
+	tests := []struct {
+		dataType string
+		expected searchKind
+	}{
+		{"int", equalSearch},
+		{"bit", equalSearch},
+		{"nchar", likeSearch},
+		{"datetimeoffset", rangeSearch},
+		{"varbinary", noSearch},
+		{"geography", noSearch},
+		{"my_alias_type", noSearch},
+	}
+
+	for _, test := range tests {
+		if kind := getSearchKind(Column{data_type: test.dataType}); kind != test.expected {
+			t.Errorf("getSearchKind(%s) = %d, expected %d", test.dataType, kind, test.expected)
+		}
+	}
+}
+
+func TestSearchParameterNames(t *testing.T) {
+	dataTable := DataTable{schema: "dbo", name: "Orders", columns: []Column{
+		{column_name: "HireDate", data_type: "date", column_id: 1},
+		{column_name: "HireDateFrom", data_type: "int", column_id: 2},
+		{column_name: "Order_Date", data_type: "int", column_id: 3},
+		{column_name: "OrderDate", data_type: "int", column_id: 4},
+		{column_name: "row", data_type: "int", column_id: 5},
+		{column_name: "class", data_type: "int", column_id: 6},
+	}}
+	setMemberNames(&dataTable)
+
+	parameters, err := dataTable.SearchParameters()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlNames := make([]string, 0)
+	csNames := make([]string, 0)
+	for _, parameter := range parameters {
+		sqlNames = append(sqlNames, parameter.Name())
+		csNames = append(csNames, parameter.CsName())
+	}
+
+	expected := []string{"@HireDateFrom2", "@HireDateTo", "@HireDateFrom", "@Order_Date", "@OrderDate", "@rowValue", "@class"}
+	if !reflect.DeepEqual(sqlNames, expected) {
+		t.Errorf("the sproc parameters are %q, expected %q", sqlNames, expected)
+	}
+	expected = []string{"hireDateFrom2", "hireDateTo", "hireDateFrom", "orderDate", "orderDate2", "rowValue", "@class"}
+	if !reflect.DeepEqual(csNames, expected) {
+		t.Errorf("the Search() arguments are %q, expected %q", csNames, expected)
+	}
+}
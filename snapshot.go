@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// schemaSnapshot is the on-disk form of the table details, so code can be
+// generated without a database. The field names follow sys.columns.
+type schemaSnapshot struct {
+	Server   string          `json:"server" yaml:"server"`
+	Database string          `json:"database" yaml:"database"`
+	Tables   []tableSnapshot `json:"tables" yaml:"tables"`
+}
+
+type tableSnapshot struct {
+	Schema  string              `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Name    string              `json:"name" yaml:"name"`
+	Columns []columnSnapshot    `json:"columns" yaml:"columns"`
+	Grants  map[string][]string `json:"grants,omitempty" yaml:"grants,omitempty"`
+}
+
+type columnSnapshot struct {
+	Name       string `json:"name" yaml:"name"`
+	DataType   string `json:"data_type" yaml:"data_type"`
+	MaxLength  int    `json:"max_length" yaml:"max_length"`
+	Precision  int    `json:"precision" yaml:"precision"`
+	Scale      int    `json:"scale" yaml:"scale"`
+	ColumnId   int    `json:"column_id" yaml:"column_id"`
+	IsIdentity bool   `json:"is_identity,omitempty" yaml:"is_identity,omitempty"`
+	IsComputed bool   `json:"is_computed,omitempty" yaml:"is_computed,omitempty"`
+	IsNullable bool   `json:"is_nullable,omitempty" yaml:"is_nullable,omitempty"`
+	KeyOrdinal int    `json:"key_ordinal,omitempty" yaml:"key_ordinal,omitempty"`
+	Default    string `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// isYaml decides the snapshot format from the file extension, JSON otherwise
+func isYaml(fileName string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// readSnapshot loads a JSON or YAML snapshot file
+func readSnapshot(fileName string) (schemaSnapshot, error) {
+	snapshot := schemaSnapshot{}
+
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return snapshot, err
+	}
+
+	if isYaml(fileName) {
+		err = yaml.Unmarshal(data, &snapshot)
+	} else {
+		err = json.Unmarshal(data, &snapshot)
+	}
+	if err != nil {
+		return snapshot, fmt.Errorf("reading %s failed: %s", fileName, err)
+	}
+
+	return snapshot, nil
+}
+
+// writeSnapshot saves the snapshot to a file, or to stdout if there's no file name
+func writeSnapshot(fileName string, snapshot schemaSnapshot) error {
+	var data []byte
+	var err error
+
+	if isYaml(fileName) {
+		data, err = yaml.Marshal(snapshot)
+	} else {
+		data, err = json.MarshalIndent(snapshot, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	if fileName == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+// snapshotProvider serves the tables from a snapshot file
+type snapshotProvider struct {
+	memoryProvider
+}
+
+// newSnapshotProvider reads the snapshot file. The snapshot's database is used
+// for the generated code unless -database was given.
+func newSnapshotProvider(fileName string) (*snapshotProvider, error) {
+	snapshot, err := readSnapshot(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot.Database != "" && !isFlagSet("database") {
+		*database = snapshot.Database
+	}
+
+	provider := &snapshotProvider{}
+	for _, t := range snapshot.Tables {
+		provider.dataTables = append(provider.dataTables, t.dataTable())
+	}
+	return provider, nil
+}
+
+// dataTable builds the dataTable from the snapshot, the same as if it
+// came from the database
+func (t tableSnapshot) dataTable() DataTable {
+	dataTable := DataTable{schema: t.Schema, name: t.Name}
+	if dataTable.schema == "" {
+		dataTable.schema = defaultSchema
+	}
+	// older snapshots kept the sproc names as they were deployed
+	dataTable.grants = filterGrants(t.Grants, dataTable)
+	for _, c := range t.Columns {
+		dataTable.columns = append(dataTable.columns, Column{
+			dataTable_name: t.Name,
+			column_name:    c.Name,
+			data_type:      c.DataType,
+			max_length:     c.MaxLength,
+			precision:      c.Precision,
+			scale:          c.Scale,
+			column_id:      c.ColumnId,
+			is_identity:    c.IsIdentity,
+			is_computed:    c.IsComputed,
+			is_nullable:    c.IsNullable,
+			key_ordinal:    c.KeyOrdinal,
+			default_value:  c.Default,
+		})
+	}
+	return dataTable
+}
+
+// newTableSnapshot converts a dataTable to its snapshot form
+func newTableSnapshot(dataTable DataTable) tableSnapshot {
+	t := tableSnapshot{Schema: getSchemaName(dataTable), Name: dataTable.name, Grants: dataTable.grants}
+	for _, column := range dataTable.columns {
+		t.Columns = append(t.Columns, columnSnapshot{
+			Name:       column.column_name,
+			DataType:   column.data_type,
+			MaxLength:  column.max_length,
+			Precision:  column.precision,
+			Scale:      column.scale,
+			ColumnId:   column.column_id,
+			IsIdentity: column.is_identity,
+			IsComputed: column.is_computed,
+			IsNullable: column.is_nullable,
+			KeyOrdinal: column.key_ordinal,
+			Default:    column.default_value,
+		})
+	}
+	return t
+}
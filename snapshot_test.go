@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(saved string) { *database = saved }(*database)
+
+	dataTable := DataTable{schema: "dbo", name: "Employee", columns: []Column{
+		{dataTable_name: "Employee", column_name: "EmployeeId", data_type: "int", max_length: 4, precision: 10, column_id: 1, is_identity: true, key_ordinal: 1},
+		{dataTable_name: "Employee", column_name: "Name", data_type: "nvarchar", max_length: 100, column_id: 2},
+		{dataTable_name: "Employee", column_name: "HireDate", data_type: "datetime2", max_length: 7, precision: 23, scale: 3, column_id: 3, is_nullable: true, default_value: "(getdate())"},
+		{dataTable_name: "Employee", column_name: "FullName", data_type: "nvarchar", max_length: 202, column_id: 4, is_computed: true, is_nullable: true},
+	}, grants: map[string][]string{"[dbo].[stp_employee_ins]": {"AppUsers"}}}
+
+	for _, fileName := range []string{"schema.json", "schema.yaml"} {
+		fileName = filepath.Join(dir, fileName)
+		snapshot := schemaSnapshot{Server: "server", Database: "Payroll", Tables: []tableSnapshot{newTableSnapshot(dataTable)}}
+		if err := writeSnapshot(fileName, snapshot); err != nil {
+			t.Fatal(err)
+		}
+
+		provider, err := newSnapshotProvider(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := provider.LoadDataTable("employee")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(loaded, dataTable) {
+			t.Errorf("%s: loaded\n%+v\nexpected\n%+v", fileName, loaded, dataTable)
+		}
+
+		if *database != "Payroll" {
+			t.Errorf("%s: the database is %q, expected the snapshot's Payroll", fileName, *database)
+		}
+		if _, err := provider.LoadDataTable("Nope"); err == nil {
+			t.Errorf("%s: loading a missing table should fail", fileName)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var templateDir = flag.String("templates", "", "directory of templates that override the built-in ones, by file name")
+
+// the built-in templates, one per file, named after the file
+//
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// templates holds the built-in templates with any -templates overrides applied
+var templates *template.Template
+
+// templateFuncs are the helpers available to every template
+var templateFuncs = template.FuncMap{
+	"database":             func() string { return *database },
+	"server":               func() string { return *server },
+	"sqlStyle":             func() string { return *sqlStyle },
+	"sqlParameter":         getMetaData,
+	"sqlString":            sqlString,
+	"sqlType":              getSqlTypeDeclaration,
+	"quoteName":            quoteName,
+	"sqlOptionalParameter": getOptionalMetaData,
+	"saveStyle":            func() string { return *saveStyle },
+	"csRead":               getClassDataRead,
+	"csType":               getClassDataType,
+	"csDefault":            getClassDataTypeDefault,
+	"csAssign":             getClassDataAssignment,
+	"csAddParameter":       getClassAddParameter,
+	"csColumnType":         getClassColumnType,
+	"csRowValue":           getClassRowValue,
+	"csString":             csString,
+	"usings":               getClassUsings,
+	"pascal":               pascalCase,
+	"camel":                camelCase,
+	"lower":                strings.ToLower,
+	"upper":                strings.ToUpper,
+	"join":                 strings.Join,
+}
+
+// loadTemplates parses the built-in templates, then any .tmpl files in dir.
+// A file with the same name as a built-in one replaces it, new files can be
+// pulled in with {{template "name.tmpl" .}}.
+func loadTemplates(dir string) (*template.Template, error) {
+	t, err := template.New("").Funcs(templateFuncs).ParseFS(defaultTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		return t, nil
+	}
+
+	fileNames, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, fileName := range fileNames {
+		text, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := t.New(filepath.Base(fileName)).Parse(string(text)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// executeTemplate runs the named template against the data
+func executeTemplate(name string, data interface{}) (string, error) {
+	var buffer bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&buffer, name, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// pascalCase turns a name like hourly_wage or hourlyWage into HourlyWage
+func pascalCase(name string) string {
+	var buffer bytes.Buffer
+
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == ' ' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			buffer.WriteRune(unicode.ToUpper(r))
+			upper = false
+		} else {
+			buffer.WriteRune(r)
+		}
+	}
+	return buffer.String()
+}
+
+// camelCase turns a name like HourlyWage or hourly_wage into hourlyWage
+func camelCase(name string) string {
+	name = pascalCase(name)
+	for i, r := range name {
+		return string(unicode.ToLower(r)) + name[i+len(string(r)):]
+	}
+	return name
+}
+
+// The templates can't see unexported fields, so the model is exposed through these.
+
+func (dataTable DataTable) Name() string      { return dataTable.name }
+func (dataTable DataTable) Schema() string    { return getSchemaName(dataTable) }
+func (dataTable DataTable) Columns() []Column { return dataTable.columns }
+
+// ClassName returns the C# class name, the table name made safe
+func (dataTable DataTable) ClassName() string { return getClassName(dataTable) }
+
+// QualifiedName returns the quoted schema and table, i.e. [hr].[Employee]
+func (dataTable DataTable) QualifiedName() string {
+	return quoteName(getSchemaName(dataTable)) + "." + quoteName(dataTable.name)
+}
+
+// Namespace returns the C# namespace, tables outside dbo get their own so the classes don't collide
+func (dataTable DataTable) Namespace() string {
+	if strings.EqualFold(getSchemaName(dataTable), defaultSchema) {
+		return *database
+	}
+	return *database + "." + pascalCase(getSchemaName(dataTable))
+}
+
+// KeyColumns returns the primary key, or the identity if there isn't one
+func (dataTable DataTable) KeyColumns() []Column { return getKeyColumns(dataTable) }
+func (dataTable DataTable) HasKey() bool         { return hasKey(dataTable) }
+
+// IsKey tells us if the column is one of the key fields
+func (dataTable DataTable) IsKey(column Column) bool { return isKeyColumn(dataTable, column) }
+
+// Identity returns the identity column, nil if the table doesn't have one
+func (dataTable DataTable) Identity() *Column {
+	for _, column := range dataTable.columns {
+		if column.is_identity {
+			return &column
+		}
+	}
+	return nil
+}
+
+// RowVersion returns the rowversion column, nil if the table doesn't have one
+func (dataTable DataTable) RowVersion() *Column {
+	for _, column := range dataTable.columns {
+		if isRowVersion(column) {
+			return &column
+		}
+	}
+	return nil
+}
+
+// InsertColumns are the columns the insert sproc takes, everything but the identity, computed and rowversion ones
+func (dataTable DataTable) InsertColumns() []Column {
+	columns := make([]Column, 0)
+	for _, column := range dataTable.columns {
+		if !(column.is_identity || column.is_computed || isRowVersion(column)) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// UpdateColumns are the columns the update sproc takes, everything but the computed ones.
+// The rowversion is left out too, the update sproc adds it on the end.
+func (dataTable DataTable) UpdateColumns() []Column {
+	columns := make([]Column, 0)
+	for _, column := range dataTable.columns {
+		if !(column.is_computed || isRowVersion(column)) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// SetColumns are the columns the update sproc changes, the ones that aren't part of the key
+func (dataTable DataTable) SetColumns() []Column {
+	columns := make([]Column, 0)
+	for _, column := range dataTable.UpdateColumns() {
+		if !(column.is_identity || isKeyColumn(dataTable, column)) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// SortColumns are the columns the list sproc can sort on
+func (dataTable DataTable) SortColumns() []Column {
+	columns := make([]Column, 0)
+	for _, column := range dataTable.columns {
+		if isSortable(column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// SprocName returns the name of the table's sproc for an action, i.e. stp_Employee_ins
+func (dataTable DataTable) SprocName(action string) string {
+	return "stp_" + dataTable.name + "_" + action
+}
+
+// QualifiedSprocName returns the quoted sproc name in the table's schema, i.e. [hr].[stp_Employee_ins]
+func (dataTable DataTable) QualifiedSprocName(action string) string {
+	return quoteName(getSchemaName(dataTable)) + "." + quoteName(dataTable.SprocName(action))
+}
+
+func (column Column) Name() string     { return column.column_name }
+func (column Column) DataType() string { return column.data_type }
+func (column Column) MaxLength() int   { return column.max_length }
+func (column Column) Precision() int   { return column.precision }
+func (column Column) Scale() int       { return column.scale }
+func (column Column) ColumnId() int    { return column.column_id }
+func (column Column) IsIdentity() bool { return column.is_identity }
+func (column Column) IsComputed() bool { return column.is_computed }
+func (column Column) IsNullable() bool { return column.is_nullable }
+func (column Column) KeyOrdinal() int  { return column.key_ordinal }
+func (column Column) Default() string  { return column.default_value }
+
+// QuotedName returns the column name for the SQL, i.e. [Order]
+func (column Column) QuotedName() string { return quoteName(column.column_name) }
+
+// MemberName returns the C# property for the column, i.e. Order_Date or @class
+func (column Column) MemberName() string { return getClassMemberName(column) }
+
+// ParameterName returns the sproc parameter for the column, i.e. @Order_Date
+func (column Column) ParameterName() string { return getParameterName(column) }
+
+func (column Column) DataTableName() string { return column.dataTable_name }
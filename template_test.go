@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		name   string
+		pascal string
+		camel  string
+	}{
+		{"hourly_wage", "HourlyWage", "hourlyWage"},
+		{"HourlyWage", "HourlyWage", "hourlyWage"},
+		{"hire date", "HireDate", "hireDate"},
+		{"id", "Id", "id"},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		if pascal := pascalCase(test.name); pascal != test.pascal {
+			t.Errorf("pascalCase(%q) = %q, expected %q", test.name, pascal, test.pascal)
+		}
+		if camel := camelCase(test.name); camel != test.camel {
+			t.Errorf("camelCase(%q) = %q, expected %q", test.name, camel, test.camel)
+		}
+	}
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// replace a built-in template and add a new one that it pulls in
+	overrides := map[string]string{
+		"sql_drop.tmpl":  `{{template "banner.tmpl" .}}drop proc {{.}}` + "\n",
+		"banner.tmpl":    "-- custom\n",
+		"not_a_template": "{{ignored",
+	}
+	for fileName, text := range overrides {
+		if err := ioutil.WriteFile(filepath.Join(dir, fileName), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defer func(saved *template.Template) { templates = saved }(templates)
+	if templates, err = loadTemplates(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := makeSqlCode(employeeTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(code, "-- custom\ndrop proc [dbo].[stp_Employee_ins]\n") {
+		t.Errorf("the sql_drop.tmpl override wasn't used:\n%s", code)
+	}
+	if !strings.Contains(code, "CREATE proc [dbo].[stp_Employee_ins]") {
+		t.Errorf("the built-in sql_insert.tmpl should still be used:\n%s", code)
+	}
+}
@@ -0,0 +1,243 @@
+using System;
+using System.Collections.Generic;
+using System.Data;
+using System.Data.SqlClient;
+using FECUtil;
+// <custom usings>
+// </custom>
+
+namespace Internal {
+	/// <summary>
+	/// this class is used for all common functionality for a record in the
+	/// Account dataTable in the Internal database on the fecsql03 server
+
+	/// </summary>
+	/// <returns></returns>
+	public class Account
+	{
+		public Account()
+		{
+			Balance = 0m;
+			RowVer = null;
+		}
+
+		public long AccountId { get; set; }
+		public decimal Balance { get; set; }
+		public byte[] RowVer { get; set; }
+
+		/// <summary>
+		/// Save() will decide to call insert or update for you.
+		/// </summary>
+		/// <returns></returns>
+		public int Save()
+		{
+			int iReturn = 0;
+			if (AccountId > 0)
+			{
+				Update();
+				iReturn = Convert.ToInt32(AccountId);
+			}
+			else
+				iReturn = Insert();
+			return iReturn;
+		}
+		private int Insert()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Account_ins]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, true);
+			cmd.Parameters["@AccountId"].Direction = ParameterDirection.Output;
+			SqlParameter version = cmd.Parameters.Add("@RowVer", SqlDbType.Timestamp);
+			version.Direction = ParameterDirection.Output;
+
+			cmd.ExecuteNonQuery();
+			RowVer = (byte[])version.Value;
+			AccountId = Convert.ToInt64(cmd.Parameters["@AccountId"].Value);
+			iReturn = Convert.ToInt32(AccountId);
+			return iReturn;
+		}
+
+		private int Update()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Account_upd]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, true);
+			SqlParameter version = cmd.Parameters.Add("@RowVer", SqlDbType.Timestamp);
+			version.Value = (object)RowVer ?? DBNull.Value;
+			version.Direction = ParameterDirection.InputOutput;
+
+			iReturn = cmd.ExecuteNonQuery();
+			if (iReturn == 0)
+				throw new DBConcurrencyException("Account was changed or deleted since it was loaded");
+			RowVer = (byte[])version.Value;
+			return iReturn;
+		}
+
+		private void addParameters(SqlCommand cmd, bool isUpdate = false)
+		{
+			if (isUpdate)
+				cmd.Parameters.AddWithValue("@AccountId", AccountId);
+			cmd.Parameters.AddWithValue("@Balance", Balance);
+		}
+
+		public void Delete()
+		{
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Account_del]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			cmd.Parameters.AddWithValue("@AccountId", AccountId);
+			cmd.Parameters.Add("@RowVer", SqlDbType.Timestamp).Value = (object)RowVer ?? DBNull.Value;
+			if (cmd.ExecuteNonQuery() == 0)
+				throw new DBConcurrencyException("Account was changed or deleted since it was loaded");
+		}
+
+		public bool Load()
+		{
+			bool bResult = false;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Account_sel]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@AccountId", AccountId);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			if (dt.Rows.Count > 0)
+				bResult = loadFromRow(dt.Rows[0]);
+			conn.Close();
+			return bResult;
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records sorted on the sortBy column, null sorts on the key.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Account> LoadPage(int page, int size, string sortBy)
+		{
+			int totalCount;
+			return LoadPage(page, size, sortBy, false, out totalCount);
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records, totalCount is the number of records in all the pages.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Account> LoadPage(int page, int size, string sortBy, bool descending, out int totalCount)
+		{
+			List<Account> list = new List<Account>();
+			SqlConnection conn = new Account().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Account_list]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@Page", page);
+			cmd.Parameters.AddWithValue("@PageSize", size);
+			cmd.Parameters.AddWithValue("@SortBy", (object)sortBy ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@SortDesc", descending);
+			SqlParameter total = cmd.Parameters.Add("@TotalCount", SqlDbType.Int);
+			total.Direction = ParameterDirection.Output;
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				Account record = new Account();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			totalCount = Convert.ToInt32(total.Value);
+			return list;
+		}
+
+		/// <summary>
+		/// LoadAll() returns every record in the table.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Account> LoadAll()
+		{
+			return LoadPage(1, int.MaxValue, null);
+		}
+
+		/// <summary>
+		/// Search() returns the records that match all the arguments given, null ones are left out.
+		/// Strings are compared with LIKE, so they can have % and _ wildcards.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Account> Search(long? accountId = null, decimal? balance = null)
+		{
+			List<Account> list = new List<Account>();
+			SqlConnection conn = new Account().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Account_search]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@AccountId", (object)accountId ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@Balance", (object)balance ?? DBNull.Value);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				Account record = new Account();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			return list;
+		}
+
+		/// <summary>
+		/// BulkSave() sends all the records to the server in one call, as a table-valued parameter.
+		/// Records that are already there are updated, the rest are inserted.
+		/// If any of them were changed since they were loaded nothing is saved, and it throws a SqlException.
+		/// The new versions don't come back, load the records again to save them after this.
+		/// </summary>
+		public static void BulkSave(IEnumerable<Account> records)
+		{
+			DataTable rows = new DataTable();
+			rows.Columns.Add("AccountId", typeof(long));
+			rows.Columns.Add("Balance", typeof(decimal));
+			rows.Columns.Add("RowVer", typeof(byte[]));
+			foreach (Account record in records)
+				rows.Rows.Add(record.AccountId > 0 ? (object)record.AccountId : DBNull.Value, record.Balance, (object)record.RowVer ?? DBNull.Value);
+
+			SqlConnection conn = new Account().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Account_bulk_upsert]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			SqlParameter parameter = cmd.Parameters.AddWithValue("@Rows", rows);
+			parameter.SqlDbType = SqlDbType.Structured;
+			parameter.TypeName = "[dbo].[Account_tvp]";
+			cmd.ExecuteNonQuery();
+			conn.Close();
+		}
+
+		public bool loadFromRow(DataRow row)
+		{
+			bool bResult = false;
+
+			AccountId = Convert.ToInt64(row["AccountId"]);
+			Balance = Convert.ToDecimal(row["Balance"]);
+			RowVer = (byte[])row["RowVer"];
+			bResult = true;
+			return bResult;
+		}
+		public SqlConnection getConnection() {
+		
+			SqlConnection conn = Database.getSqlConnection("Internal");
+			return conn;
+		}
+
+		// <custom>
+		// </custom>
+	}
+}
@@ -0,0 +1,209 @@
+use [Internal]
+
+
+-- ******** INSERT ********
+if object_id('[dbo].[stp_Account_ins]', 'P') is not null
+	drop proc [dbo].[stp_Account_ins]
+go
+CREATE proc [dbo].[stp_Account_ins] 
+	@Balance money ,
+	@AccountId bigint  OUTPUT,
+	@RowVer binary(8) = NULL OUTPUT
+AS
+DECLARE @versions TABLE ([RowVer] binary(8))
+insert into [dbo].[Account] ([Balance])
+OUTPUT inserted.[RowVer] INTO @versions
+VALUES (@Balance)
+SET @AccountId = scope_identity()
+SELECT @RowVer = [RowVer] FROM @versions
+go
+
+-- ******** UPDATE ********
+if object_id('[dbo].[stp_Account_upd]', 'P') is not null
+	drop proc [dbo].[stp_Account_upd]
+go
+CREATE proc [dbo].[stp_Account_upd] 
+	@AccountId bigint ,
+	@Balance money ,
+	@RowVer binary(8) OUTPUT
+AS
+DECLARE @versions TABLE ([RowVer] binary(8))
+update [dbo].[Account]
+SET [Balance] = @Balance
+OUTPUT inserted.[RowVer] INTO @versions
+WHERE [AccountId] = @AccountId AND [RowVer] = @RowVer
+
+SELECT @RowVer = [RowVer] FROM @versions
+go
+
+-- ******** DELETE ********
+if object_id('[dbo].[stp_Account_del]', 'P') is not null
+	drop proc [dbo].[stp_Account_del]
+go
+CREATE proc [dbo].[stp_Account_del] 
+	@AccountId bigint ,
+	@RowVer binary(8) 
+AS
+DELETE FROM [dbo].[Account]
+
+WHERE [AccountId] = @AccountId AND [RowVer] = @RowVer
+go
+
+-- ******** READ ********
+if object_id('[dbo].[stp_Account_sel]', 'P') is not null
+	drop proc [dbo].[stp_Account_sel]
+go
+CREATE proc [dbo].[stp_Account_sel] 
+	@AccountId bigint 
+AS
+SELECT [AccountId], [Balance], [RowVer]
+FROM [dbo].[Account]
+WHERE [AccountId] = @AccountId
+go
+
+-- ******** UPSERT ********
+if object_id('[dbo].[stp_Account_upsert]', 'P') is not null
+	drop proc [dbo].[stp_Account_upsert]
+go
+CREATE proc [dbo].[stp_Account_upsert] 
+	@AccountId bigint = NULL OUTPUT,
+	@Balance money ,
+	@RowVer binary(8) = NULL OUTPUT
+AS
+SET XACT_ABORT ON
+DECLARE @versions TABLE ([RowVer] binary(8))
+
+BEGIN TRAN
+
+IF EXISTS (SELECT 1 FROM [dbo].[Account] WITH (UPDLOCK, HOLDLOCK)
+	WHERE [AccountId] = @AccountId)
+UPDATE [dbo].[Account] WITH (UPDLOCK, HOLDLOCK)
+SET [Balance] = @Balance
+OUTPUT inserted.[RowVer] INTO @versions
+WHERE [AccountId] = @AccountId AND [RowVer] = @RowVer
+ELSE
+BEGIN
+	INSERT INTO [dbo].[Account] ([Balance])
+	OUTPUT inserted.[RowVer] INTO @versions
+	VALUES (@Balance)
+	SET @AccountId = scope_identity()
+END
+
+SET @RowVer = (SELECT [RowVer] FROM @versions)
+
+COMMIT
+go
+
+-- ******** LIST ********
+if object_id('[dbo].[stp_Account_list]', 'P') is not null
+	drop proc [dbo].[stp_Account_list]
+go
+CREATE proc [dbo].[stp_Account_list] 
+	@Page int = 1,
+	@PageSize int = 50,
+	@SortBy nvarchar(128) = NULL,
+	@SortDesc bit = 0,
+	@TotalCount int = NULL OUTPUT
+AS
+SET NOCOUNT ON
+
+-- only the columns in the ORDER BY can be sorted on
+IF @SortBy IS NOT NULL AND @SortBy NOT IN ('AccountId', 'Balance', 'RowVer')
+BEGIN
+	RAISERROR('%s can''t be sorted on', 16, 1, @SortBy)
+	RETURN
+END
+
+IF @Page < 1
+	SET @Page = 1
+IF @PageSize < 1
+	SET @PageSize = 1
+
+SELECT @TotalCount = count(*)
+FROM [dbo].[Account]
+
+SELECT [AccountId], [Balance], [RowVer]
+FROM [dbo].[Account]
+ORDER BY
+	CASE WHEN @SortBy = 'AccountId' AND @SortDesc = 0 THEN [AccountId] END,
+	CASE WHEN @SortBy = 'AccountId' AND @SortDesc = 1 THEN [AccountId] END DESC,
+	CASE WHEN @SortBy = 'Balance' AND @SortDesc = 0 THEN [Balance] END,
+	CASE WHEN @SortBy = 'Balance' AND @SortDesc = 1 THEN [Balance] END DESC,
+	CASE WHEN @SortBy = 'RowVer' AND @SortDesc = 0 THEN [RowVer] END,
+	CASE WHEN @SortBy = 'RowVer' AND @SortDesc = 1 THEN [RowVer] END DESC,
+	[AccountId]
+OFFSET (@Page - 1) * @PageSize ROWS
+FETCH NEXT @PageSize ROWS ONLY
+go
+
+-- ******** SEARCH ********
+if object_id('[dbo].[stp_Account_search]', 'P') is not null
+	drop proc [dbo].[stp_Account_search]
+go
+CREATE proc [dbo].[stp_Account_search] 
+	@AccountId bigint = NULL ,
+	@Balance money = NULL 
+AS
+SET NOCOUNT ON
+
+SELECT [AccountId], [Balance], [RowVer]
+FROM [dbo].[Account]
+WHERE (@AccountId IS NULL OR [AccountId] = @AccountId)
+AND (@Balance IS NULL OR [Balance] = @Balance)
+OPTION (RECOMPILE)
+go
+
+-- ******** BULK ********
+if object_id('[dbo].[stp_Account_bulk_ins]', 'P') is not null
+	drop proc [dbo].[stp_Account_bulk_ins]
+go
+if object_id('[dbo].[stp_Account_bulk_upsert]', 'P') is not null
+	drop proc [dbo].[stp_Account_bulk_upsert]
+go
+if type_id('[dbo].[Account_tvp]') is not null
+	drop type [dbo].[Account_tvp]
+go
+CREATE TYPE [dbo].[Account_tvp] AS TABLE (
+	[AccountId] bigint NULL,
+	[Balance] money NOT NULL,
+	[RowVer] binary(8) NULL
+)
+go
+CREATE proc [dbo].[stp_Account_bulk_ins]
+	@Rows [dbo].[Account_tvp] READONLY
+AS
+insert into [dbo].[Account] ([Balance])
+
+SELECT [Balance]
+FROM @Rows
+go
+CREATE proc [dbo].[stp_Account_bulk_upsert]
+	@Rows [dbo].[Account_tvp] READONLY
+AS
+SET XACT_ABORT ON
+
+BEGIN TRAN
+
+IF EXISTS (SELECT 1 FROM [dbo].[Account] t WITH (UPDLOCK, HOLDLOCK)
+	JOIN @Rows r ON t.[AccountId] = r.[AccountId]
+	WHERE r.[RowVer] IS NULL OR t.[RowVer] <> r.[RowVer])
+BEGIN
+	ROLLBACK
+	RAISERROR('%s records were changed since they were loaded', 16, 1, 'Account')
+	RETURN
+END
+
+UPDATE t
+SET [Balance] = r.[Balance]
+FROM [dbo].[Account] t WITH (UPDLOCK, HOLDLOCK)
+JOIN @Rows r ON t.[AccountId] = r.[AccountId]
+
+INSERT INTO [dbo].[Account] ([Balance])
+SELECT r.[Balance]
+FROM @Rows r
+WHERE NOT EXISTS (SELECT 1 FROM [dbo].[Account] t WITH (UPDLOCK, HOLDLOCK)
+	WHERE t.[AccountId] = r.[AccountId])
+
+COMMIT
+go
+
@@ -0,0 +1,177 @@
+using System;
+using System.Collections.Generic;
+using System.Data;
+using System.Data.SqlClient;
+using FECUtil;
+// <custom usings>
+// </custom>
+
+namespace Internal {
+	/// <summary>
+	/// this class is used for all common functionality for a record in the
+	/// AppLog dataTable in the Internal database on the fecsql03 server
+
+	/// </summary>
+	/// <returns></returns>
+	public class AppLog
+	{
+		public AppLog()
+		{
+			Logged = DateTime.Parse("1/1/1900");
+			Message = null;
+		}
+
+		public DateTime Logged { get; set; }
+		public string Message { get; set; }
+
+		/// <summary>
+		/// Save() will decide to call insert or update for you.
+		/// </summary>
+		/// <returns></returns>
+		public int Save()
+		{
+			int iReturn = 0;
+			// AppLog has no key, so records can only be inserted
+			iReturn = Insert();
+			return iReturn;
+		}
+		private int Insert()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_AppLog_ins]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, false);
+
+			iReturn = cmd.ExecuteNonQuery();
+			return iReturn;
+		}
+
+		private void addParameters(SqlCommand cmd, bool isUpdate = false)
+		{
+			cmd.Parameters.AddWithValue("@Logged", Logged);
+			cmd.Parameters.AddWithValue("@Message", (object)Message ?? DBNull.Value);
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records sorted on the sortBy column, null sorts on the key.
+		/// </summary>
+		/// <returns></returns>
+		public static List<AppLog> LoadPage(int page, int size, string sortBy)
+		{
+			int totalCount;
+			return LoadPage(page, size, sortBy, false, out totalCount);
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records, totalCount is the number of records in all the pages.
+		/// </summary>
+		/// <returns></returns>
+		public static List<AppLog> LoadPage(int page, int size, string sortBy, bool descending, out int totalCount)
+		{
+			List<AppLog> list = new List<AppLog>();
+			SqlConnection conn = new AppLog().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_AppLog_list]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@Page", page);
+			cmd.Parameters.AddWithValue("@PageSize", size);
+			cmd.Parameters.AddWithValue("@SortBy", (object)sortBy ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@SortDesc", descending);
+			SqlParameter total = cmd.Parameters.Add("@TotalCount", SqlDbType.Int);
+			total.Direction = ParameterDirection.Output;
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				AppLog record = new AppLog();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			totalCount = Convert.ToInt32(total.Value);
+			return list;
+		}
+
+		/// <summary>
+		/// LoadAll() returns every record in the table.
+		/// </summary>
+		/// <returns></returns>
+		public static List<AppLog> LoadAll()
+		{
+			return LoadPage(1, int.MaxValue, null);
+		}
+
+		/// <summary>
+		/// Search() returns the records that match all the arguments given, null ones are left out.
+		/// Strings are compared with LIKE, so they can have % and _ wildcards.
+		/// </summary>
+		/// <returns></returns>
+		public static List<AppLog> Search(DateTime? loggedFrom = null, DateTime? loggedTo = null, string message = null)
+		{
+			List<AppLog> list = new List<AppLog>();
+			SqlConnection conn = new AppLog().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_AppLog_search]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@LoggedFrom", (object)loggedFrom ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@LoggedTo", (object)loggedTo ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@Message", (object)message ?? DBNull.Value);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				AppLog record = new AppLog();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			return list;
+		}
+
+		/// <summary>
+		/// BulkSave() sends all the records to the server in one call, as a table-valued parameter.
+		/// They're all inserted.
+		/// </summary>
+		public static void BulkSave(IEnumerable<AppLog> records)
+		{
+			DataTable rows = new DataTable();
+			rows.Columns.Add("Logged", typeof(DateTime));
+			rows.Columns.Add("Message", typeof(string));
+			foreach (AppLog record in records)
+				rows.Rows.Add(record.Logged, (object)record.Message ?? DBNull.Value);
+
+			SqlConnection conn = new AppLog().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_AppLog_bulk_ins]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			SqlParameter parameter = cmd.Parameters.AddWithValue("@Rows", rows);
+			parameter.SqlDbType = SqlDbType.Structured;
+			parameter.TypeName = "[dbo].[AppLog_tvp]";
+			cmd.ExecuteNonQuery();
+			conn.Close();
+		}
+
+		public bool loadFromRow(DataRow row)
+		{
+			bool bResult = false;
+
+			Logged = Convert.ToDateTime(row["Logged"]);
+			Message = row["Message"] == DBNull.Value ? null : row["Message"].ToString();
+			bResult = true;
+			return bResult;
+		}
+		public SqlConnection getConnection() {
+		
+			SqlConnection conn = Database.getSqlConnection("Internal");
+			return conn;
+		}
+
+		// <custom>
+		// </custom>
+	}
+}
@@ -0,0 +1,99 @@
+use [Internal]
+
+
+-- ******** INSERT ********
+if object_id('[dbo].[stp_AppLog_ins]', 'P') is not null
+	drop proc [dbo].[stp_AppLog_ins]
+go
+CREATE proc [dbo].[stp_AppLog_ins] 
+	@Logged datetime2(3) ,
+	@Message nvarchar(MAX) = NULL 
+AS
+insert into [dbo].[AppLog] ([Logged], [Message])
+
+VALUES (@Logged, @Message)
+go
+
+-- ******** LIST ********
+if object_id('[dbo].[stp_AppLog_list]', 'P') is not null
+	drop proc [dbo].[stp_AppLog_list]
+go
+CREATE proc [dbo].[stp_AppLog_list] 
+	@Page int = 1,
+	@PageSize int = 50,
+	@SortBy nvarchar(128) = NULL,
+	@SortDesc bit = 0,
+	@TotalCount int = NULL OUTPUT
+AS
+SET NOCOUNT ON
+
+-- only the columns in the ORDER BY can be sorted on
+IF @SortBy IS NOT NULL AND @SortBy NOT IN ('Logged', 'Message')
+BEGIN
+	RAISERROR('%s can''t be sorted on', 16, 1, @SortBy)
+	RETURN
+END
+
+IF @Page < 1
+	SET @Page = 1
+IF @PageSize < 1
+	SET @PageSize = 1
+
+SELECT @TotalCount = count(*)
+FROM [dbo].[AppLog]
+
+SELECT [Logged], [Message]
+FROM [dbo].[AppLog]
+ORDER BY
+	CASE WHEN @SortBy = 'Logged' AND @SortDesc = 0 THEN [Logged] END,
+	CASE WHEN @SortBy = 'Logged' AND @SortDesc = 1 THEN [Logged] END DESC,
+	CASE WHEN @SortBy = 'Message' AND @SortDesc = 0 THEN [Message] END,
+	CASE WHEN @SortBy = 'Message' AND @SortDesc = 1 THEN [Message] END DESC,
+	(SELECT NULL)
+OFFSET (@Page - 1) * @PageSize ROWS
+FETCH NEXT @PageSize ROWS ONLY
+go
+
+-- ******** SEARCH ********
+if object_id('[dbo].[stp_AppLog_search]', 'P') is not null
+	drop proc [dbo].[stp_AppLog_search]
+go
+CREATE proc [dbo].[stp_AppLog_search] 
+	@LoggedFrom datetime2(3) = NULL ,
+	@LoggedTo datetime2(3) = NULL ,
+	@Message nvarchar(MAX) = NULL 
+AS
+SET NOCOUNT ON
+
+SELECT [Logged], [Message]
+FROM [dbo].[AppLog]
+WHERE (@LoggedFrom IS NULL OR [Logged] >= @LoggedFrom)
+AND (@LoggedTo IS NULL OR [Logged] <= @LoggedTo)
+AND (@Message IS NULL OR [Message] LIKE @Message)
+OPTION (RECOMPILE)
+go
+
+-- ******** BULK ********
+if object_id('[dbo].[stp_AppLog_bulk_ins]', 'P') is not null
+	drop proc [dbo].[stp_AppLog_bulk_ins]
+go
+if object_id('[dbo].[stp_AppLog_bulk_upsert]', 'P') is not null
+	drop proc [dbo].[stp_AppLog_bulk_upsert]
+go
+if type_id('[dbo].[AppLog_tvp]') is not null
+	drop type [dbo].[AppLog_tvp]
+go
+CREATE TYPE [dbo].[AppLog_tvp] AS TABLE (
+	[Logged] datetime2(3) NOT NULL,
+	[Message] nvarchar(MAX) NULL
+)
+go
+CREATE proc [dbo].[stp_AppLog_bulk_ins]
+	@Rows [dbo].[AppLog_tvp] READONLY
+AS
+insert into [dbo].[AppLog] ([Logged], [Message])
+
+SELECT [Logged], [Message]
+FROM @Rows
+go
+
@@ -0,0 +1,213 @@
+using System;
+using System.Collections.Generic;
+using System.Data;
+using System.Data.SqlClient;
+using FECUtil;
+// <custom usings>
+// </custom>
+
+namespace Internal {
+	/// <summary>
+	/// this class is used for all common functionality for a record in the
+	/// EmpRole dataTable in the Internal database on the fecsql03 server
+
+	/// </summary>
+	/// <returns></returns>
+	public class EmpRole
+	{
+		public EmpRole()
+		{
+			EmpId = 0;
+			RoleId = 0;
+		}
+
+		public int EmpId { get; set; }
+		public int RoleId { get; set; }
+
+		/// <summary>
+		/// Save() will decide to call insert or update for you.
+		/// </summary>
+		/// <returns></returns>
+		public int Save()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_EmpRole_upsert]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, true);
+
+			iReturn = cmd.ExecuteNonQuery();
+			conn.Close();
+			return iReturn;
+		}
+		private int Insert()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_EmpRole_ins]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, false);
+
+			iReturn = cmd.ExecuteNonQuery();
+			return iReturn;
+		}
+
+		private void addParameters(SqlCommand cmd, bool isUpdate = false)
+		{
+			cmd.Parameters.AddWithValue("@EmpId", EmpId);
+			cmd.Parameters.AddWithValue("@RoleId", RoleId);
+		}
+
+		public void Delete()
+		{
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_EmpRole_del]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			cmd.Parameters.AddWithValue("@EmpId", EmpId);
+			cmd.Parameters.AddWithValue("@RoleId", RoleId);
+			cmd.ExecuteNonQuery();
+		}
+
+		public bool Load()
+		{
+			bool bResult = false;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_EmpRole_sel]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@EmpId", EmpId);
+			cmd.Parameters.AddWithValue("@RoleId", RoleId);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			if (dt.Rows.Count > 0)
+				bResult = loadFromRow(dt.Rows[0]);
+			conn.Close();
+			return bResult;
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records sorted on the sortBy column, null sorts on the key.
+		/// </summary>
+		/// <returns></returns>
+		public static List<EmpRole> LoadPage(int page, int size, string sortBy)
+		{
+			int totalCount;
+			return LoadPage(page, size, sortBy, false, out totalCount);
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records, totalCount is the number of records in all the pages.
+		/// </summary>
+		/// <returns></returns>
+		public static List<EmpRole> LoadPage(int page, int size, string sortBy, bool descending, out int totalCount)
+		{
+			List<EmpRole> list = new List<EmpRole>();
+			SqlConnection conn = new EmpRole().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_EmpRole_list]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@Page", page);
+			cmd.Parameters.AddWithValue("@PageSize", size);
+			cmd.Parameters.AddWithValue("@SortBy", (object)sortBy ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@SortDesc", descending);
+			SqlParameter total = cmd.Parameters.Add("@TotalCount", SqlDbType.Int);
+			total.Direction = ParameterDirection.Output;
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				EmpRole record = new EmpRole();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			totalCount = Convert.ToInt32(total.Value);
+			return list;
+		}
+
+		/// <summary>
+		/// LoadAll() returns every record in the table.
+		/// </summary>
+		/// <returns></returns>
+		public static List<EmpRole> LoadAll()
+		{
+			return LoadPage(1, int.MaxValue, null);
+		}
+
+		/// <summary>
+		/// Search() returns the records that match all the arguments given, null ones are left out.
+		/// Strings are compared with LIKE, so they can have % and _ wildcards.
+		/// </summary>
+		/// <returns></returns>
+		public static List<EmpRole> Search(int? empId = null, int? roleId = null)
+		{
+			List<EmpRole> list = new List<EmpRole>();
+			SqlConnection conn = new EmpRole().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_EmpRole_search]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@EmpId", (object)empId ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@RoleId", (object)roleId ?? DBNull.Value);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				EmpRole record = new EmpRole();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			return list;
+		}
+
+		/// <summary>
+		/// BulkSave() sends all the records to the server in one call, as a table-valued parameter.
+		/// Records that are already there are updated, the rest are inserted.
+		/// </summary>
+		public static void BulkSave(IEnumerable<EmpRole> records)
+		{
+			DataTable rows = new DataTable();
+			rows.Columns.Add("EmpId", typeof(int));
+			rows.Columns.Add("RoleId", typeof(int));
+			foreach (EmpRole record in records)
+				rows.Rows.Add(record.EmpId, record.RoleId);
+
+			SqlConnection conn = new EmpRole().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_EmpRole_bulk_upsert]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			SqlParameter parameter = cmd.Parameters.AddWithValue("@Rows", rows);
+			parameter.SqlDbType = SqlDbType.Structured;
+			parameter.TypeName = "[dbo].[EmpRole_tvp]";
+			cmd.ExecuteNonQuery();
+			conn.Close();
+		}
+
+		public bool loadFromRow(DataRow row)
+		{
+			bool bResult = false;
+
+			EmpId = Convert.ToInt32(row["EmpId"]);
+			RoleId = Convert.ToInt32(row["RoleId"]);
+			bResult = true;
+			return bResult;
+		}
+		public SqlConnection getConnection() {
+		
+			SqlConnection conn = Database.getSqlConnection("Internal");
+			return conn;
+		}
+
+		// <custom>
+		// </custom>
+	}
+}
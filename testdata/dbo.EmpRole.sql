@@ -0,0 +1,160 @@
+use [Internal]
+
+
+-- ******** INSERT ********
+if object_id('[dbo].[stp_EmpRole_ins]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_ins]
+go
+CREATE proc [dbo].[stp_EmpRole_ins] 
+	@EmpId int ,
+	@RoleId int 
+AS
+insert into [dbo].[EmpRole] ([EmpId], [RoleId])
+
+VALUES (@EmpId, @RoleId)
+go
+
+-- ******** DELETE ********
+if object_id('[dbo].[stp_EmpRole_del]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_del]
+go
+CREATE proc [dbo].[stp_EmpRole_del] 
+	@EmpId int ,
+	@RoleId int 
+AS
+DELETE FROM [dbo].[EmpRole]
+
+WHERE [EmpId] = @EmpId AND [RoleId] = @RoleId
+go
+
+-- ******** READ ********
+if object_id('[dbo].[stp_EmpRole_sel]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_sel]
+go
+CREATE proc [dbo].[stp_EmpRole_sel] 
+	@EmpId int ,
+	@RoleId int 
+AS
+SELECT [EmpId], [RoleId]
+FROM [dbo].[EmpRole]
+WHERE [EmpId] = @EmpId AND [RoleId] = @RoleId
+go
+
+-- ******** UPSERT ********
+if object_id('[dbo].[stp_EmpRole_upsert]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_upsert]
+go
+CREATE proc [dbo].[stp_EmpRole_upsert] 
+	@EmpId int OUTPUT,
+	@RoleId int OUTPUT
+AS
+SET XACT_ABORT ON
+
+BEGIN TRAN
+
+IF NOT EXISTS (SELECT 1 FROM [dbo].[EmpRole] WITH (UPDLOCK, HOLDLOCK)
+	WHERE [EmpId] = @EmpId AND [RoleId] = @RoleId)
+BEGIN
+	INSERT INTO [dbo].[EmpRole] ([EmpId], [RoleId])
+	VALUES (@EmpId, @RoleId)
+END
+
+COMMIT
+go
+
+-- ******** LIST ********
+if object_id('[dbo].[stp_EmpRole_list]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_list]
+go
+CREATE proc [dbo].[stp_EmpRole_list] 
+	@Page int = 1,
+	@PageSize int = 50,
+	@SortBy nvarchar(128) = NULL,
+	@SortDesc bit = 0,
+	@TotalCount int = NULL OUTPUT
+AS
+SET NOCOUNT ON
+
+-- only the columns in the ORDER BY can be sorted on
+IF @SortBy IS NOT NULL AND @SortBy NOT IN ('EmpId', 'RoleId')
+BEGIN
+	RAISERROR('%s can''t be sorted on', 16, 1, @SortBy)
+	RETURN
+END
+
+IF @Page < 1
+	SET @Page = 1
+IF @PageSize < 1
+	SET @PageSize = 1
+
+SELECT @TotalCount = count(*)
+FROM [dbo].[EmpRole]
+
+SELECT [EmpId], [RoleId]
+FROM [dbo].[EmpRole]
+ORDER BY
+	CASE WHEN @SortBy = 'EmpId' AND @SortDesc = 0 THEN [EmpId] END,
+	CASE WHEN @SortBy = 'EmpId' AND @SortDesc = 1 THEN [EmpId] END DESC,
+	CASE WHEN @SortBy = 'RoleId' AND @SortDesc = 0 THEN [RoleId] END,
+	CASE WHEN @SortBy = 'RoleId' AND @SortDesc = 1 THEN [RoleId] END DESC,
+	[EmpId], [RoleId]
+OFFSET (@Page - 1) * @PageSize ROWS
+FETCH NEXT @PageSize ROWS ONLY
+go
+
+-- ******** SEARCH ********
+if object_id('[dbo].[stp_EmpRole_search]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_search]
+go
+CREATE proc [dbo].[stp_EmpRole_search] 
+	@EmpId int = NULL ,
+	@RoleId int = NULL 
+AS
+SET NOCOUNT ON
+
+SELECT [EmpId], [RoleId]
+FROM [dbo].[EmpRole]
+WHERE (@EmpId IS NULL OR [EmpId] = @EmpId)
+AND (@RoleId IS NULL OR [RoleId] = @RoleId)
+OPTION (RECOMPILE)
+go
+
+-- ******** BULK ********
+if object_id('[dbo].[stp_EmpRole_bulk_ins]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_bulk_ins]
+go
+if object_id('[dbo].[stp_EmpRole_bulk_upsert]', 'P') is not null
+	drop proc [dbo].[stp_EmpRole_bulk_upsert]
+go
+if type_id('[dbo].[EmpRole_tvp]') is not null
+	drop type [dbo].[EmpRole_tvp]
+go
+CREATE TYPE [dbo].[EmpRole_tvp] AS TABLE (
+	[EmpId] int NOT NULL,
+	[RoleId] int NOT NULL
+)
+go
+CREATE proc [dbo].[stp_EmpRole_bulk_ins]
+	@Rows [dbo].[EmpRole_tvp] READONLY
+AS
+insert into [dbo].[EmpRole] ([EmpId], [RoleId])
+
+SELECT [EmpId], [RoleId]
+FROM @Rows
+go
+CREATE proc [dbo].[stp_EmpRole_bulk_upsert]
+	@Rows [dbo].[EmpRole_tvp] READONLY
+AS
+SET XACT_ABORT ON
+
+BEGIN TRAN
+
+INSERT INTO [dbo].[EmpRole] ([EmpId], [RoleId])
+SELECT r.[EmpId], r.[RoleId]
+FROM @Rows r
+WHERE NOT EXISTS (SELECT 1 FROM [dbo].[EmpRole] t WITH (UPDLOCK, HOLDLOCK)
+	WHERE t.[EmpId] = r.[EmpId] AND t.[RoleId] = r.[RoleId])
+
+COMMIT
+go
+
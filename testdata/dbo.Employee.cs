@@ -0,0 +1,243 @@
+using System;
+using System.Collections.Generic;
+using System.Data;
+using System.Data.SqlClient;
+using FECUtil;
+// <custom usings>
+// </custom>
+
+namespace Internal {
+	/// <summary>
+	/// this class is used for all common functionality for a record in the
+	/// Employee dataTable in the Internal database on the fecsql03 server
+
+	/// </summary>
+	/// <returns></returns>
+	public class Employee
+	{
+		public Employee()
+		{
+			Name = string.Empty;
+			HourlyWage = 0m;
+			HireDate = null;
+			FullName = null;
+		}
+
+		public int EmployeeId { get; set; }
+		public string Name { get; set; }
+		public decimal HourlyWage { get; set; }
+		public DateTime? HireDate { get; set; }
+		public string FullName { get; set; }
+
+		/// <summary>
+		/// Save() will decide to call insert or update for you.
+		/// </summary>
+		/// <returns></returns>
+		public int Save()
+		{
+			int iReturn = 0;
+			if (EmployeeId > 0)
+			{
+				Update();
+				iReturn = EmployeeId;
+			}
+			else
+				iReturn = Insert();
+			return iReturn;
+		}
+		private int Insert()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Employee_ins]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, true);
+			cmd.Parameters["@EmployeeId"].Direction = ParameterDirection.Output;
+
+			cmd.ExecuteNonQuery();
+			EmployeeId = Convert.ToInt32(cmd.Parameters["@EmployeeId"].Value);
+			iReturn = EmployeeId;
+			return iReturn;
+		}
+
+		private int Update()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Employee_upd]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, true);
+
+			iReturn = cmd.ExecuteNonQuery();
+			return iReturn;
+		}
+
+		private void addParameters(SqlCommand cmd, bool isUpdate = false)
+		{
+			if (isUpdate)
+				cmd.Parameters.AddWithValue("@EmployeeId", EmployeeId);
+			cmd.Parameters.AddWithValue("@Name", Name);
+			cmd.Parameters.AddWithValue("@HourlyWage", HourlyWage);
+			cmd.Parameters.AddWithValue("@HireDate", (object)HireDate ?? DBNull.Value);
+		}
+
+		public void Delete()
+		{
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Employee_del]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			cmd.Parameters.AddWithValue("@EmployeeId", EmployeeId);
+			cmd.ExecuteNonQuery();
+		}
+
+		public bool Load()
+		{
+			bool bResult = false;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Employee_sel]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@EmployeeId", EmployeeId);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			if (dt.Rows.Count > 0)
+				bResult = loadFromRow(dt.Rows[0]);
+			conn.Close();
+			return bResult;
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records sorted on the sortBy column, null sorts on the key.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Employee> LoadPage(int page, int size, string sortBy)
+		{
+			int totalCount;
+			return LoadPage(page, size, sortBy, false, out totalCount);
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records, totalCount is the number of records in all the pages.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Employee> LoadPage(int page, int size, string sortBy, bool descending, out int totalCount)
+		{
+			List<Employee> list = new List<Employee>();
+			SqlConnection conn = new Employee().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Employee_list]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@Page", page);
+			cmd.Parameters.AddWithValue("@PageSize", size);
+			cmd.Parameters.AddWithValue("@SortBy", (object)sortBy ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@SortDesc", descending);
+			SqlParameter total = cmd.Parameters.Add("@TotalCount", SqlDbType.Int);
+			total.Direction = ParameterDirection.Output;
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				Employee record = new Employee();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			totalCount = Convert.ToInt32(total.Value);
+			return list;
+		}
+
+		/// <summary>
+		/// LoadAll() returns every record in the table.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Employee> LoadAll()
+		{
+			return LoadPage(1, int.MaxValue, null);
+		}
+
+		/// <summary>
+		/// Search() returns the records that match all the arguments given, null ones are left out.
+		/// Strings are compared with LIKE, so they can have % and _ wildcards.
+		/// </summary>
+		/// <returns></returns>
+		public static List<Employee> Search(int? employeeId = null, string name = null, decimal? hourlyWage = null, DateTime? hireDateFrom = null, DateTime? hireDateTo = null, string fullName = null)
+		{
+			List<Employee> list = new List<Employee>();
+			SqlConnection conn = new Employee().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Employee_search]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@EmployeeId", (object)employeeId ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@Name", (object)name ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@HourlyWage", (object)hourlyWage ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@HireDateFrom", (object)hireDateFrom ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@HireDateTo", (object)hireDateTo ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@FullName", (object)fullName ?? DBNull.Value);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				Employee record = new Employee();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			return list;
+		}
+
+		/// <summary>
+		/// BulkSave() sends all the records to the server in one call, as a table-valued parameter.
+		/// Records that are already there are updated, the rest are inserted.
+		/// </summary>
+		public static void BulkSave(IEnumerable<Employee> records)
+		{
+			DataTable rows = new DataTable();
+			rows.Columns.Add("EmployeeId", typeof(int));
+			rows.Columns.Add("Name", typeof(string));
+			rows.Columns.Add("HourlyWage", typeof(decimal));
+			rows.Columns.Add("HireDate", typeof(DateTime));
+			foreach (Employee record in records)
+				rows.Rows.Add(record.EmployeeId > 0 ? (object)record.EmployeeId : DBNull.Value, record.Name, record.HourlyWage, (object)record.HireDate ?? DBNull.Value);
+
+			SqlConnection conn = new Employee().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[dbo].[stp_Employee_bulk_upsert]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			SqlParameter parameter = cmd.Parameters.AddWithValue("@Rows", rows);
+			parameter.SqlDbType = SqlDbType.Structured;
+			parameter.TypeName = "[dbo].[Employee_tvp]";
+			cmd.ExecuteNonQuery();
+			conn.Close();
+		}
+
+		public bool loadFromRow(DataRow row)
+		{
+			bool bResult = false;
+
+			EmployeeId = Convert.ToInt32(row["EmployeeId"]);
+			Name = row["Name"].ToString();
+			HourlyWage = Convert.ToDecimal(row["HourlyWage"]);
+			HireDate = row["HireDate"] == DBNull.Value ? (DateTime?)null : Convert.ToDateTime(row["HireDate"]);
+			FullName = row["FullName"] == DBNull.Value ? null : row["FullName"].ToString();
+			bResult = true;
+			return bResult;
+		}
+		public SqlConnection getConnection() {
+		
+			SqlConnection conn = Database.getSqlConnection("Internal");
+			return conn;
+		}
+
+		// <custom>
+		// </custom>
+	}
+}
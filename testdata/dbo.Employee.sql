@@ -0,0 +1,203 @@
+use [Internal]
+
+
+-- ******** INSERT ********
+if object_id('[dbo].[stp_Employee_ins]', 'P') is not null
+	drop proc [dbo].[stp_Employee_ins]
+go
+CREATE proc [dbo].[stp_Employee_ins] 
+	@Name nvarchar(50) ,
+	@HourlyWage decimal(10, 3) ,
+	@HireDate date = NULL ,
+	@EmployeeId int  OUTPUT
+AS
+insert into [dbo].[Employee] ([Name], [HourlyWage], [HireDate])
+
+VALUES (@Name, @HourlyWage, @HireDate)
+SET @EmployeeId = scope_identity()
+go
+
+-- ******** UPDATE ********
+if object_id('[dbo].[stp_Employee_upd]', 'P') is not null
+	drop proc [dbo].[stp_Employee_upd]
+go
+CREATE proc [dbo].[stp_Employee_upd] 
+	@EmployeeId int ,
+	@Name nvarchar(50) ,
+	@HourlyWage decimal(10, 3) ,
+	@HireDate date = NULL 
+AS
+update [dbo].[Employee]
+SET [Name] = @Name, [HourlyWage] = @HourlyWage, [HireDate] = @HireDate
+WHERE [EmployeeId] = @EmployeeId
+go
+
+-- ******** DELETE ********
+if object_id('[dbo].[stp_Employee_del]', 'P') is not null
+	drop proc [dbo].[stp_Employee_del]
+go
+CREATE proc [dbo].[stp_Employee_del] 
+	@EmployeeId int 
+AS
+DELETE FROM [dbo].[Employee]
+
+WHERE [EmployeeId] = @EmployeeId
+go
+
+-- ******** READ ********
+if object_id('[dbo].[stp_Employee_sel]', 'P') is not null
+	drop proc [dbo].[stp_Employee_sel]
+go
+CREATE proc [dbo].[stp_Employee_sel] 
+	@EmployeeId int 
+AS
+SELECT [EmployeeId], [Name], [HourlyWage], [HireDate], [FullName]
+FROM [dbo].[Employee]
+WHERE [EmployeeId] = @EmployeeId
+go
+
+-- ******** UPSERT ********
+if object_id('[dbo].[stp_Employee_upsert]', 'P') is not null
+	drop proc [dbo].[stp_Employee_upsert]
+go
+CREATE proc [dbo].[stp_Employee_upsert] 
+	@EmployeeId int = NULL OUTPUT,
+	@Name nvarchar(50) ,
+	@HourlyWage decimal(10, 3) ,
+	@HireDate date = NULL 
+AS
+SET XACT_ABORT ON
+
+BEGIN TRAN
+
+UPDATE [dbo].[Employee] WITH (UPDLOCK, HOLDLOCK)
+SET [Name] = @Name, [HourlyWage] = @HourlyWage, [HireDate] = @HireDate
+WHERE [EmployeeId] = @EmployeeId
+
+IF @@ROWCOUNT = 0
+BEGIN
+	INSERT INTO [dbo].[Employee] ([Name], [HourlyWage], [HireDate])
+	VALUES (@Name, @HourlyWage, @HireDate)
+	SET @EmployeeId = scope_identity()
+END
+
+COMMIT
+go
+
+-- ******** LIST ********
+if object_id('[dbo].[stp_Employee_list]', 'P') is not null
+	drop proc [dbo].[stp_Employee_list]
+go
+CREATE proc [dbo].[stp_Employee_list] 
+	@Page int = 1,
+	@PageSize int = 50,
+	@SortBy nvarchar(128) = NULL,
+	@SortDesc bit = 0,
+	@TotalCount int = NULL OUTPUT
+AS
+SET NOCOUNT ON
+
+-- only the columns in the ORDER BY can be sorted on
+IF @SortBy IS NOT NULL AND @SortBy NOT IN ('EmployeeId', 'Name', 'HourlyWage', 'HireDate', 'FullName')
+BEGIN
+	RAISERROR('%s can''t be sorted on', 16, 1, @SortBy)
+	RETURN
+END
+
+IF @Page < 1
+	SET @Page = 1
+IF @PageSize < 1
+	SET @PageSize = 1
+
+SELECT @TotalCount = count(*)
+FROM [dbo].[Employee]
+
+SELECT [EmployeeId], [Name], [HourlyWage], [HireDate], [FullName]
+FROM [dbo].[Employee]
+ORDER BY
+	CASE WHEN @SortBy = 'EmployeeId' AND @SortDesc = 0 THEN [EmployeeId] END,
+	CASE WHEN @SortBy = 'EmployeeId' AND @SortDesc = 1 THEN [EmployeeId] END DESC,
+	CASE WHEN @SortBy = 'Name' AND @SortDesc = 0 THEN [Name] END,
+	CASE WHEN @SortBy = 'Name' AND @SortDesc = 1 THEN [Name] END DESC,
+	CASE WHEN @SortBy = 'HourlyWage' AND @SortDesc = 0 THEN [HourlyWage] END,
+	CASE WHEN @SortBy = 'HourlyWage' AND @SortDesc = 1 THEN [HourlyWage] END DESC,
+	CASE WHEN @SortBy = 'HireDate' AND @SortDesc = 0 THEN [HireDate] END,
+	CASE WHEN @SortBy = 'HireDate' AND @SortDesc = 1 THEN [HireDate] END DESC,
+	CASE WHEN @SortBy = 'FullName' AND @SortDesc = 0 THEN [FullName] END,
+	CASE WHEN @SortBy = 'FullName' AND @SortDesc = 1 THEN [FullName] END DESC,
+	[EmployeeId]
+OFFSET (@Page - 1) * @PageSize ROWS
+FETCH NEXT @PageSize ROWS ONLY
+go
+
+-- ******** SEARCH ********
+if object_id('[dbo].[stp_Employee_search]', 'P') is not null
+	drop proc [dbo].[stp_Employee_search]
+go
+CREATE proc [dbo].[stp_Employee_search] 
+	@EmployeeId int = NULL ,
+	@Name nvarchar(102) = NULL ,
+	@HourlyWage decimal(10, 3) = NULL ,
+	@HireDateFrom date = NULL ,
+	@HireDateTo date = NULL ,
+	@FullName nvarchar(204) = NULL 
+AS
+SET NOCOUNT ON
+
+SELECT [EmployeeId], [Name], [HourlyWage], [HireDate], [FullName]
+FROM [dbo].[Employee]
+WHERE (@EmployeeId IS NULL OR [EmployeeId] = @EmployeeId)
+AND (@Name IS NULL OR [Name] LIKE @Name)
+AND (@HourlyWage IS NULL OR [HourlyWage] = @HourlyWage)
+AND (@HireDateFrom IS NULL OR [HireDate] >= @HireDateFrom)
+AND (@HireDateTo IS NULL OR [HireDate] <= @HireDateTo)
+AND (@FullName IS NULL OR [FullName] LIKE @FullName)
+OPTION (RECOMPILE)
+go
+
+-- ******** BULK ********
+if object_id('[dbo].[stp_Employee_bulk_ins]', 'P') is not null
+	drop proc [dbo].[stp_Employee_bulk_ins]
+go
+if object_id('[dbo].[stp_Employee_bulk_upsert]', 'P') is not null
+	drop proc [dbo].[stp_Employee_bulk_upsert]
+go
+if type_id('[dbo].[Employee_tvp]') is not null
+	drop type [dbo].[Employee_tvp]
+go
+CREATE TYPE [dbo].[Employee_tvp] AS TABLE (
+	[EmployeeId] int NULL,
+	[Name] nvarchar(50) NOT NULL,
+	[HourlyWage] decimal(10, 3) NOT NULL,
+	[HireDate] date NULL
+)
+go
+CREATE proc [dbo].[stp_Employee_bulk_ins]
+	@Rows [dbo].[Employee_tvp] READONLY
+AS
+insert into [dbo].[Employee] ([Name], [HourlyWage], [HireDate])
+
+SELECT [Name], [HourlyWage], [HireDate]
+FROM @Rows
+go
+CREATE proc [dbo].[stp_Employee_bulk_upsert]
+	@Rows [dbo].[Employee_tvp] READONLY
+AS
+SET XACT_ABORT ON
+
+BEGIN TRAN
+
+UPDATE t
+SET [Name] = r.[Name], [HourlyWage] = r.[HourlyWage], [HireDate] = r.[HireDate]
+FROM [dbo].[Employee] t WITH (UPDLOCK, HOLDLOCK)
+JOIN @Rows r ON t.[EmployeeId] = r.[EmployeeId]
+
+INSERT INTO [dbo].[Employee] ([Name], [HourlyWage], [HireDate])
+SELECT r.[Name], r.[HourlyWage], r.[HireDate]
+FROM @Rows r
+WHERE NOT EXISTS (SELECT 1 FROM [dbo].[Employee] t WITH (UPDLOCK, HOLDLOCK)
+	WHERE t.[EmployeeId] = r.[EmployeeId])
+
+COMMIT
+go
+
@@ -0,0 +1,233 @@
+using System;
+using System.Collections.Generic;
+using System.Data;
+using System.Data.SqlClient;
+using FECUtil;
+// <custom usings>
+// </custom>
+
+namespace Internal.Sales {
+	/// <summary>
+	/// this class is used for all common functionality for a record in the
+	/// OrderLine dataTable in the Internal database on the fecsql03 server
+
+	/// </summary>
+	/// <returns></returns>
+	public class OrderLine
+	{
+		public OrderLine()
+		{
+			OrderId = 0;
+			LineNo = 0;
+			Quantity = 0;
+			Price = null;
+		}
+
+		public int OrderId { get; set; }
+		public short LineNo { get; set; }
+		public int Quantity { get; set; }
+		public decimal? Price { get; set; }
+
+		/// <summary>
+		/// Save() will decide to call insert or update for you.
+		/// </summary>
+		/// <returns></returns>
+		public int Save()
+		{
+			int iReturn = 0;
+			iReturn = Update();
+			if (iReturn == 0)
+				iReturn = Insert();
+			return iReturn;
+		}
+		private int Insert()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[sales].[stp_OrderLine_ins]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, false);
+
+			iReturn = cmd.ExecuteNonQuery();
+			return iReturn;
+		}
+
+		private int Update()
+		{
+			int iReturn = 0;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[sales].[stp_OrderLine_upd]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			addParameters(cmd, true);
+
+			iReturn = cmd.ExecuteNonQuery();
+			return iReturn;
+		}
+
+		private void addParameters(SqlCommand cmd, bool isUpdate = false)
+		{
+			cmd.Parameters.AddWithValue("@OrderId", OrderId);
+			cmd.Parameters.AddWithValue("@LineNo", LineNo);
+			cmd.Parameters.AddWithValue("@Quantity", Quantity);
+			cmd.Parameters.AddWithValue("@Price", (object)Price ?? DBNull.Value);
+		}
+
+		public void Delete()
+		{
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[sales].[stp_OrderLine_del]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+
+			cmd.Parameters.AddWithValue("@OrderId", OrderId);
+			cmd.Parameters.AddWithValue("@LineNo", LineNo);
+			cmd.ExecuteNonQuery();
+		}
+
+		public bool Load()
+		{
+			bool bResult = false;
+			SqlConnection conn = getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[sales].[stp_OrderLine_sel]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@OrderId", OrderId);
+			cmd.Parameters.AddWithValue("@LineNo", LineNo);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			if (dt.Rows.Count > 0)
+				bResult = loadFromRow(dt.Rows[0]);
+			conn.Close();
+			return bResult;
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records sorted on the sortBy column, null sorts on the key.
+		/// </summary>
+		/// <returns></returns>
+		public static List<OrderLine> LoadPage(int page, int size, string sortBy)
+		{
+			int totalCount;
+			return LoadPage(page, size, sortBy, false, out totalCount);
+		}
+
+		/// <summary>
+		/// LoadPage() returns a page of records, totalCount is the number of records in all the pages.
+		/// </summary>
+		/// <returns></returns>
+		public static List<OrderLine> LoadPage(int page, int size, string sortBy, bool descending, out int totalCount)
+		{
+			List<OrderLine> list = new List<OrderLine>();
+			SqlConnection conn = new OrderLine().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[sales].[stp_OrderLine_list]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@Page", page);
+			cmd.Parameters.AddWithValue("@PageSize", size);
+			cmd.Parameters.AddWithValue("@SortBy", (object)sortBy ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@SortDesc", descending);
+			SqlParameter total = cmd.Parameters.Add("@TotalCount", SqlDbType.Int);
+			total.Direction = ParameterDirection.Output;
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				OrderLine record = new OrderLine();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			totalCount = Convert.ToInt32(total.Value);
+			return list;
+		}
+
+		/// <summary>
+		/// LoadAll() returns every record in the table.
+		/// </summary>
+		/// <returns></returns>
+		public static List<OrderLine> LoadAll()
+		{
+			return LoadPage(1, int.MaxValue, null);
+		}
+
+		/// <summary>
+		/// Search() returns the records that match all the arguments given, null ones are left out.
+		/// Strings are compared with LIKE, so they can have % and _ wildcards.
+		/// </summary>
+		/// <returns></returns>
+		public static List<OrderLine> Search(int? orderId = null, short? lineNo = null, int? quantity = null, decimal? price = null)
+		{
+			List<OrderLine> list = new List<OrderLine>();
+			SqlConnection conn = new OrderLine().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[sales].[stp_OrderLine_search]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			cmd.Parameters.AddWithValue("@OrderId", (object)orderId ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@LineNo", (object)lineNo ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@Quantity", (object)quantity ?? DBNull.Value);
+			cmd.Parameters.AddWithValue("@Price", (object)price ?? DBNull.Value);
+
+			DataTable dt = new DataTable();
+			dt.Load(cmd.ExecuteReader());
+			foreach (DataRow row in dt.Rows)
+			{
+				OrderLine record = new OrderLine();
+				record.loadFromRow(row);
+				list.Add(record);
+			}
+			conn.Close();
+			return list;
+		}
+
+		/// <summary>
+		/// BulkSave() sends all the records to the server in one call, as a table-valued parameter.
+		/// Records that are already there are updated, the rest are inserted.
+		/// </summary>
+		public static void BulkSave(IEnumerable<OrderLine> records)
+		{
+			DataTable rows = new DataTable();
+			rows.Columns.Add("OrderId", typeof(int));
+			rows.Columns.Add("LineNo", typeof(short));
+			rows.Columns.Add("Quantity", typeof(int));
+			rows.Columns.Add("Price", typeof(decimal));
+			foreach (OrderLine record in records)
+				rows.Rows.Add(record.OrderId, record.LineNo, record.Quantity, (object)record.Price ?? DBNull.Value);
+
+			SqlConnection conn = new OrderLine().getConnection();
+			conn.Open();
+			SqlCommand cmd = new SqlCommand("[sales].[stp_OrderLine_bulk_upsert]", conn);
+			cmd.CommandType = CommandType.StoredProcedure;
+			SqlParameter parameter = cmd.Parameters.AddWithValue("@Rows", rows);
+			parameter.SqlDbType = SqlDbType.Structured;
+			parameter.TypeName = "[sales].[OrderLine_tvp]";
+			cmd.ExecuteNonQuery();
+			conn.Close();
+		}
+
+		public bool loadFromRow(DataRow row)
+		{
+			bool bResult = false;
+
+			OrderId = Convert.ToInt32(row["OrderId"]);
+			LineNo = Convert.ToInt16(row["LineNo"]);
+			Quantity = Convert.ToInt32(row["Quantity"]);
+			Price = row["Price"] == DBNull.Value ? (decimal?)null : Convert.ToDecimal(row["Price"]);
+			bResult = true;
+			return bResult;
+		}
+		public SqlConnection getConnection() {
+		
+			SqlConnection conn = Database.getSqlConnection("Internal");
+			return conn;
+		}
+
+		// <custom>
+		// </custom>
+	}
+}
@@ -0,0 +1,197 @@
+use [Internal]
+
+
+-- ******** INSERT ********
+if object_id('[sales].[stp_OrderLine_ins]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_ins]
+go
+CREATE proc [sales].[stp_OrderLine_ins] 
+	@OrderId int ,
+	@LineNo smallint ,
+	@Quantity int ,
+	@Price money = NULL 
+AS
+insert into [sales].[OrderLine] ([OrderId], [LineNo], [Quantity], [Price])
+
+VALUES (@OrderId, @LineNo, @Quantity, @Price)
+go
+
+-- ******** UPDATE ********
+if object_id('[sales].[stp_OrderLine_upd]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_upd]
+go
+CREATE proc [sales].[stp_OrderLine_upd] 
+	@OrderId int ,
+	@LineNo smallint ,
+	@Quantity int ,
+	@Price money = NULL 
+AS
+update [sales].[OrderLine]
+SET [Quantity] = @Quantity, [Price] = @Price
+WHERE [OrderId] = @OrderId AND [LineNo] = @LineNo
+go
+
+-- ******** DELETE ********
+if object_id('[sales].[stp_OrderLine_del]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_del]
+go
+CREATE proc [sales].[stp_OrderLine_del] 
+	@OrderId int ,
+	@LineNo smallint 
+AS
+DELETE FROM [sales].[OrderLine]
+
+WHERE [OrderId] = @OrderId AND [LineNo] = @LineNo
+go
+
+-- ******** READ ********
+if object_id('[sales].[stp_OrderLine_sel]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_sel]
+go
+CREATE proc [sales].[stp_OrderLine_sel] 
+	@OrderId int ,
+	@LineNo smallint 
+AS
+SELECT [OrderId], [LineNo], [Quantity], [Price]
+FROM [sales].[OrderLine]
+WHERE [OrderId] = @OrderId AND [LineNo] = @LineNo
+go
+
+-- ******** UPSERT ********
+if object_id('[sales].[stp_OrderLine_upsert]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_upsert]
+go
+CREATE proc [sales].[stp_OrderLine_upsert] 
+	@OrderId int OUTPUT,
+	@LineNo smallint OUTPUT,
+	@Quantity int ,
+	@Price money = NULL 
+AS
+SET XACT_ABORT ON
+
+BEGIN TRAN
+
+UPDATE [sales].[OrderLine] WITH (UPDLOCK, HOLDLOCK)
+SET [Quantity] = @Quantity, [Price] = @Price
+WHERE [OrderId] = @OrderId AND [LineNo] = @LineNo
+
+IF @@ROWCOUNT = 0
+BEGIN
+	INSERT INTO [sales].[OrderLine] ([OrderId], [LineNo], [Quantity], [Price])
+	VALUES (@OrderId, @LineNo, @Quantity, @Price)
+END
+
+COMMIT
+go
+
+-- ******** LIST ********
+if object_id('[sales].[stp_OrderLine_list]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_list]
+go
+CREATE proc [sales].[stp_OrderLine_list] 
+	@Page int = 1,
+	@PageSize int = 50,
+	@SortBy nvarchar(128) = NULL,
+	@SortDesc bit = 0,
+	@TotalCount int = NULL OUTPUT
+AS
+SET NOCOUNT ON
+
+-- only the columns in the ORDER BY can be sorted on
+IF @SortBy IS NOT NULL AND @SortBy NOT IN ('OrderId', 'LineNo', 'Quantity', 'Price')
+BEGIN
+	RAISERROR('%s can''t be sorted on', 16, 1, @SortBy)
+	RETURN
+END
+
+IF @Page < 1
+	SET @Page = 1
+IF @PageSize < 1
+	SET @PageSize = 1
+
+SELECT @TotalCount = count(*)
+FROM [sales].[OrderLine]
+
+SELECT [OrderId], [LineNo], [Quantity], [Price]
+FROM [sales].[OrderLine]
+ORDER BY
+	CASE WHEN @SortBy = 'OrderId' AND @SortDesc = 0 THEN [OrderId] END,
+	CASE WHEN @SortBy = 'OrderId' AND @SortDesc = 1 THEN [OrderId] END DESC,
+	CASE WHEN @SortBy = 'LineNo' AND @SortDesc = 0 THEN [LineNo] END,
+	CASE WHEN @SortBy = 'LineNo' AND @SortDesc = 1 THEN [LineNo] END DESC,
+	CASE WHEN @SortBy = 'Quantity' AND @SortDesc = 0 THEN [Quantity] END,
+	CASE WHEN @SortBy = 'Quantity' AND @SortDesc = 1 THEN [Quantity] END DESC,
+	CASE WHEN @SortBy = 'Price' AND @SortDesc = 0 THEN [Price] END,
+	CASE WHEN @SortBy = 'Price' AND @SortDesc = 1 THEN [Price] END DESC,
+	[OrderId], [LineNo]
+OFFSET (@Page - 1) * @PageSize ROWS
+FETCH NEXT @PageSize ROWS ONLY
+go
+
+-- ******** SEARCH ********
+if object_id('[sales].[stp_OrderLine_search]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_search]
+go
+CREATE proc [sales].[stp_OrderLine_search] 
+	@OrderId int = NULL ,
+	@LineNo smallint = NULL ,
+	@Quantity int = NULL ,
+	@Price money = NULL 
+AS
+SET NOCOUNT ON
+
+SELECT [OrderId], [LineNo], [Quantity], [Price]
+FROM [sales].[OrderLine]
+WHERE (@OrderId IS NULL OR [OrderId] = @OrderId)
+AND (@LineNo IS NULL OR [LineNo] = @LineNo)
+AND (@Quantity IS NULL OR [Quantity] = @Quantity)
+AND (@Price IS NULL OR [Price] = @Price)
+OPTION (RECOMPILE)
+go
+
+-- ******** BULK ********
+if object_id('[sales].[stp_OrderLine_bulk_ins]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_bulk_ins]
+go
+if object_id('[sales].[stp_OrderLine_bulk_upsert]', 'P') is not null
+	drop proc [sales].[stp_OrderLine_bulk_upsert]
+go
+if type_id('[sales].[OrderLine_tvp]') is not null
+	drop type [sales].[OrderLine_tvp]
+go
+CREATE TYPE [sales].[OrderLine_tvp] AS TABLE (
+	[OrderId] int NOT NULL,
+	[LineNo] smallint NOT NULL,
+	[Quantity] int NOT NULL,
+	[Price] money NULL
+)
+go
+CREATE proc [sales].[stp_OrderLine_bulk_ins]
+	@Rows [sales].[OrderLine_tvp] READONLY
+AS
+insert into [sales].[OrderLine] ([OrderId], [LineNo], [Quantity], [Price])
+
+SELECT [OrderId], [LineNo], [Quantity], [Price]
+FROM @Rows
+go
+CREATE proc [sales].[stp_OrderLine_bulk_upsert]
+	@Rows [sales].[OrderLine_tvp] READONLY
+AS
+SET XACT_ABORT ON
+
+BEGIN TRAN
+
+UPDATE t
+SET [Quantity] = r.[Quantity], [Price] = r.[Price]
+FROM [sales].[OrderLine] t WITH (UPDLOCK, HOLDLOCK)
+JOIN @Rows r ON t.[OrderId] = r.[OrderId] AND t.[LineNo] = r.[LineNo]
+
+INSERT INTO [sales].[OrderLine] ([OrderId], [LineNo], [Quantity], [Price])
+SELECT r.[OrderId], r.[LineNo], r.[Quantity], r.[Price]
+FROM @Rows r
+WHERE NOT EXISTS (SELECT 1 FROM [sales].[OrderLine] t WITH (UPDLOCK, HOLDLOCK)
+	WHERE t.[OrderId] = r.[OrderId] AND t.[LineNo] = r.[LineNo])
+
+COMMIT
+go
+
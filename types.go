@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+)
+
+// sizeKind says what goes in the brackets after the type in a parameter declaration
+type sizeKind int
+
+const (
+	noSize            sizeKind = iota
+	lengthSize                 // varchar(50), max_length is in bytes
+	unicodeLengthSize          // nvarchar(50), max_length is in bytes so it's twice the length
+	precisionSize              // decimal(10, 3)
+	fractionSize               // datetime2(3), the scale is the digits of the fraction
+)
+
+// sqlType is everything we need to know about a SqlServer type to generate code for it
+type sqlType struct {
+	parameterType string   // the type in the sproc parameter declaration
+	size          sizeKind // what size, if any, follows the parameter type
+	classType     string   // the C# type
+	classDefault  string   // what the constructor initializes the member to
+	classRead     string   // converts a DataRow value to the C# type, %s is the row["column"]
+	classUsing    string   // the namespace the C# type needs, if it's not in System
+	udt           bool     // a CLR type, the SqlParameter needs a UdtTypeName
+}
+
+// sqlTypes maps the sys.types names to how we generate code for them
+var sqlTypes = map[string]sqlType{
+	"bit":      {"bit", noSize, "bool", "false", "Convert.ToBoolean(%s)", "", false},
+	"tinyint":  {"tinyint", noSize, "byte", "0", "Convert.ToByte(%s)", "", false},
+	"smallint": {"smallint", noSize, "short", "0", "Convert.ToInt16(%s)", "", false},
+	"int":      {"int", noSize, "int", "0", "Convert.ToInt32(%s)", "", false},
+	"bigint":   {"bigint", noSize, "long", "0", "Convert.ToInt64(%s)", "", false},
+
+	"decimal":    {"decimal", precisionSize, "decimal", "0m", "Convert.ToDecimal(%s)", "", false},
+	"numeric":    {"numeric", precisionSize, "decimal", "0m", "Convert.ToDecimal(%s)", "", false},
+	"money":      {"money", noSize, "decimal", "0m", "Convert.ToDecimal(%s)", "", false},
+	"smallmoney": {"smallmoney", noSize, "decimal", "0m", "Convert.ToDecimal(%s)", "", false},
+	"float":      {"float", noSize, "double", "0.0", "Convert.ToDouble(%s)", "", false},
+	"real":       {"real", noSize, "float", "0.0f", "Convert.ToSingle(%s)", "", false},
+
+	"date":           {"date", noSize, "DateTime", `DateTime.Parse("1/1/1900")`, "Convert.ToDateTime(%s)", "", false},
+	"smalldatetime":  {"smalldatetime", noSize, "DateTime", `DateTime.Parse("1/1/1900")`, "Convert.ToDateTime(%s)", "", false},
+	"datetime":       {"datetime", noSize, "DateTime", `DateTime.Parse("1/1/1900")`, "Convert.ToDateTime(%s)", "", false},
+	"datetime2":      {"datetime2", fractionSize, "DateTime", `DateTime.Parse("1/1/1900")`, "Convert.ToDateTime(%s)", "", false},
+	"datetimeoffset": {"datetimeoffset", fractionSize, "DateTimeOffset", "DateTimeOffset.MinValue", "(DateTimeOffset)%s", "", false},
+	"time":           {"time", fractionSize, "TimeSpan", "TimeSpan.Zero", "(TimeSpan)%s", "", false},
+
+	"char":     {"char", lengthSize, "string", "string.Empty", "%s.ToString()", "", false},
+	"varchar":  {"varchar", lengthSize, "string", "string.Empty", "%s.ToString()", "", false},
+	"nchar":    {"nchar", unicodeLengthSize, "string", "string.Empty", "%s.ToString()", "", false},
+	"nvarchar": {"nvarchar", unicodeLengthSize, "string", "string.Empty", "%s.ToString()", "", false},
+	"text":     {"varchar(MAX)", noSize, "string", "string.Empty", "%s.ToString()", "", false},
+	"ntext":    {"nvarchar(MAX)", noSize, "string", "string.Empty", "%s.ToString()", "", false},
+	"xml":      {"xml", noSize, "string", "string.Empty", "%s.ToString()", "", false},
+
+	"uniqueidentifier": {"uniqueidentifier", noSize, "Guid", "Guid.Empty", "(Guid)%s", "", false},
+	"binary":           {"binary", lengthSize, "byte[]", "new byte[0]", "(byte[])%s", "", false},
+	"varbinary":        {"varbinary", lengthSize, "byte[]", "new byte[0]", "(byte[])%s", "", false},
+	"image":            {"varbinary(MAX)", noSize, "byte[]", "new byte[0]", "(byte[])%s", "", false},
+	"timestamp":        {"binary(8)", noSize, "byte[]", "null", "(byte[])%s", "", false},
+	"rowversion":       {"binary(8)", noSize, "byte[]", "null", "(byte[])%s", "", false},
+	"sql_variant":      {"sql_variant", noSize, "object", "null", "%s", "", false},
+
+	"hierarchyid": {"hierarchyid", noSize, "SqlHierarchyId", "SqlHierarchyId.Null", "(SqlHierarchyId)%s", "Microsoft.SqlServer.Types", true},
+	"geography":   {"geography", noSize, "SqlGeography", "SqlGeography.Null", "(SqlGeography)%s", "Microsoft.SqlServer.Types", true},
+	"geometry":    {"geometry", noSize, "SqlGeometry", "SqlGeometry.Null", "(SqlGeometry)%s", "Microsoft.SqlServer.Types", true},
+}
+
+// getSqlType returns the mapping for the column's type. Anything we don't
+// know about (alias types and the like) is passed through as an object.
+func getSqlType(column Column) sqlType {
+	if t, ok := sqlTypes[column.data_type]; ok {
+		return t
+	}
+	return sqlType{column.data_type, noSize, "object", "null", "%s", "", false}
+}
+
+// getMetaData returns the SQL Parameter information for a column
+// i.e. @hourlyWage decimal(10, 3)
+func getMetaData(column Column) string {
+	// nullable columns are optional parameters
+	optional := ""
+	if column.is_nullable {
+		optional = " = NULL"
+	}
+
+	return fmt.Sprintf("%s %s%s ", getParameterName(column), getSqlTypeDeclaration(column), optional)
+}
+
+// getSqlTypeDeclaration returns the column's type with its size, i.e. decimal(10, 3)
+func getSqlTypeDeclaration(column Column) string {
+	t := getSqlType(column)
+
+	size := ""
+
+	switch t.size {
+	case lengthSize, unicodeLengthSize:
+		length := column.max_length
+		if t.size == unicodeLengthSize {
+			length = length / 2
+		}
+		if column.max_length == -1 {
+			size = "(MAX)"
+		} else {
+			size = fmt.Sprintf("(%d)", length)
+		}
+	case precisionSize:
+		size = fmt.Sprintf("(%d, %d)", column.precision, column.scale)
+	case fractionSize:
+		size = fmt.Sprintf("(%d)", column.scale)
+	}
+
+	return t.parameterType + size
+}
+
+// getOptionalMetaData returns the SQL Parameter information with a NULL default,
+// for parameters the caller can leave out
+func getOptionalMetaData(column Column) string {
+	column.is_nullable = true
+	return getMetaData(column)
+}
+
+// getClassDataRead returns the expression that converts value, i.e. row["Name"], to the column's C# type
+func getClassDataRead(column Column, value string) string {
+	return fmt.Sprintf(getSqlType(column).classRead, value)
+}
+
+// isSortable tells us if SqlServer can ORDER BY the column
+func isSortable(column Column) bool {
+	switch column.data_type {
+	case "text", "ntext", "image", "xml", "geography", "geometry":
+		return false
+	}
+	return true
+}
+
+// isClassValueType tells us if the C# type is a struct that needs a ? to hold a null.
+// The CLR types have their own Null value instead.
+func isClassValueType(t sqlType) bool {
+	switch t.classType {
+	case "string", "byte[]", "object":
+		return false
+	}
+	return !t.udt
+}
+
+// getClassDataType returns the C# data type for a sql data type
+func getClassDataType(column Column) string {
+	t := getSqlType(column)
+	if column.is_nullable && isClassValueType(t) {
+		return t.classType + "?"
+	}
+	return t.classType
+}
+
+// getClassDataTypeDefault returns the variable initializer
+func getClassDataTypeDefault(column Column) string {
+	t := getSqlType(column)
+	if column.is_nullable && !t.udt {
+		return "null"
+	}
+	return t.classDefault
+}
+
+// getClassDataAssignment returns the statement that loads the member from a DataRow
+func getClassDataAssignment(column Column) string {
+	name := getClassMemberName(column)
+	t := getSqlType(column)
+	value := fmt.Sprintf("row[%s]", csString(column.column_name))
+	read := getClassDataRead(column, value)
+
+	if column.is_nullable {
+		// DBNull doesn't convert to anything, so check for it first
+		nullValue := "null"
+		if t.udt {
+			nullValue = t.classDefault
+		} else if isClassValueType(t) {
+			nullValue = fmt.Sprintf("(%s)null", getClassDataType(column))
+		}
+		read = fmt.Sprintf("%s == DBNull.Value ? %s : %s", value, nullValue, read)
+	}
+
+	return fmt.Sprintf("%s = %s;", name, read)
+}
+
+// getClassAddParameter returns the statement that adds the member to the cmd parameters
+func getClassAddParameter(column Column) string {
+	parameter := getParameterName(column)
+	name := getClassMemberName(column)
+	t := getSqlType(column)
+
+	if t.udt {
+		return fmt.Sprintf("cmd.Parameters.Add(new SqlParameter(\"%s\", SqlDbType.Udt) { UdtTypeName = \"%s\", Value = %s });", parameter, t.parameterType, name)
+	}
+	value := name
+	if column.is_nullable {
+		value = fmt.Sprintf("(object)%s ?? DBNull.Value", name)
+	}
+	// AddWithValue sends a null byte[] as an nvarchar, which doesn't convert to
+	// varbinary, so binary members say what they are
+	if dbType, size := getClassBinaryDbType(column); dbType != "" {
+		return fmt.Sprintf("cmd.Parameters.Add(\"%s\", SqlDbType.%s, %d).Value = %s;", parameter, dbType, size, value)
+	}
+	return fmt.Sprintf("cmd.Parameters.AddWithValue(\"%s\", %s);", parameter, value)
+}
+
+// getClassBinaryDbType returns the SqlDbType and size for a byte[] member, "" for anything else
+func getClassBinaryDbType(column Column) (string, int) {
+	switch column.data_type {
+	case "binary":
+		return "Binary", column.max_length
+	case "varbinary":
+		return "VarBinary", column.max_length
+	case "image":
+		// the parameter is declared varbinary(MAX)
+		return "VarBinary", -1
+	case "timestamp", "rowversion":
+		return "Timestamp", 8
+	}
+	return "", 0
+}
+
+// getClassColumnType returns the type of the column in a DataTable, nulls are
+// DBNull there so it's never the nullable one
+func getClassColumnType(column Column) string {
+	return getSqlType(column).classType
+}
+
+// getClassRowValue returns the record's member as a DataRow value, i.e. (object)record.Notes ?? DBNull.Value
+func getClassRowValue(column Column) string {
+	value := "record." + getClassMemberName(column)
+	// an identity that hasn't been inserted yet is 0, NULL tells the bulk upsert to insert it
+	if column.is_identity {
+		return fmt.Sprintf("%s > 0 ? (object)%s : DBNull.Value", value, value)
+	}
+	if column.is_nullable && !getSqlType(column).udt {
+		return fmt.Sprintf("(object)%s ?? DBNull.Value", value)
+	}
+	return value
+}
+
+// getClassUsings returns the extra namespaces the class needs for its member types
+func getClassUsings(dataTable DataTable) []string {
+	usings := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, column := range dataTable.columns {
+		if ns := getSqlType(column).classUsing; ns != "" && !seen[ns] {
+			seen[ns] = true
+			usings = append(usings, ns)
+		}
+	}
+	return usings
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassTypes(t *testing.T) {
+	tests := []struct {
+		dataType  string
+		classType string
+		read      string
+	}{
+		{"bit", "bool", `Convert.ToBoolean(row["x"])`},
+		{"tinyint", "byte", `Convert.ToByte(row["x"])`},
+		{"bigint", "long", `Convert.ToInt64(row["x"])`},
+		{"money", "decimal", `Convert.ToDecimal(row["x"])`},
+		{"real", "float", `Convert.ToSingle(row["x"])`},
+		{"datetimeoffset", "DateTimeOffset", `(DateTimeOffset)row["x"]`},
+		{"time", "TimeSpan", `(TimeSpan)row["x"]`},
+		{"ntext", "string", `row["x"].ToString()`},
+		{"uniqueidentifier", "Guid", `(Guid)row["x"]`},
+		{"varbinary", "byte[]", `(byte[])row["x"]`},
+		{"geography", "SqlGeography", `(SqlGeography)row["x"]`},
+		{"my_alias_type", "object", `row["x"]`},
+	}
+
+	for _, test := range tests {
+		column := Column{column_name: "x", data_type: test.dataType}
+		if classType := getClassDataType(column); classType != test.classType {
+			t.Errorf("%s is a C# %s, expected %s", test.dataType, classType, test.classType)
+		}
+		if assignment := getClassDataAssignment(column); assignment != "x = "+test.read+";" {
+			t.Errorf("%s is read with %q, expected %q", test.dataType, assignment, test.read)
+		}
+	}
+}
+
+func TestNullableClassTypes(t *testing.T) {
+	tests := []struct {
+		dataType     string
+		classType    string
+		classDefault string
+		read         string
+	}{
+		{"int", "int?", "null", `row["x"] == DBNull.Value ? (int?)null : Convert.ToInt32(row["x"])`},
+		{"date", "DateTime?", "null", `row["x"] == DBNull.Value ? (DateTime?)null : Convert.ToDateTime(row["x"])`},
+		{"nvarchar", "string", "null", `row["x"] == DBNull.Value ? null : row["x"].ToString()`},
+		{"varbinary", "byte[]", "null", `row["x"] == DBNull.Value ? null : (byte[])row["x"]`},
+		{"geometry", "SqlGeometry", "SqlGeometry.Null", `row["x"] == DBNull.Value ? SqlGeometry.Null : (SqlGeometry)row["x"]`},
+	}
+
+	for _, test := range tests {
+		column := Column{column_name: "x", data_type: test.dataType, is_nullable: true}
+		if classType := getClassDataType(column); classType != test.classType {
+			t.Errorf("a nullable %s is a C# %s, expected %s", test.dataType, classType, test.classType)
+		}
+		if classDefault := getClassDataTypeDefault(column); classDefault != test.classDefault {
+			t.Errorf("a nullable %s defaults to %s, expected %s", test.dataType, classDefault, test.classDefault)
+		}
+		if assignment := getClassDataAssignment(column); assignment != "x = "+test.read+";" {
+			t.Errorf("a nullable %s is read with %q, expected %q", test.dataType, assignment, test.read)
+		}
+	}
+}
+
+func TestGetMetaData(t *testing.T) {
+	tests := []struct {
+		column   Column
+		expected string
+	}{
+		{Column{column_name: "Id", data_type: "int", max_length: 4, precision: 10}, "@Id int "},
+		{Column{column_name: "Code", data_type: "char", max_length: 3}, "@Code char(3) "},
+		{Column{column_name: "Name", data_type: "nvarchar", max_length: 100}, "@Name nvarchar(50) "},
+		{Column{column_name: "Notes", data_type: "nvarchar", max_length: -1}, "@Notes nvarchar(MAX) "},
+		{Column{column_name: "Photo", data_type: "varbinary", max_length: -1}, "@Photo varbinary(MAX) "},
+		{Column{column_name: "Body", data_type: "text", max_length: 16}, "@Body varchar(MAX) "},
+		{Column{column_name: "Wage", data_type: "decimal", max_length: 9, precision: 10, scale: 3}, "@Wage decimal(10, 3) "},
+		{Column{column_name: "Ratio", data_type: "float", max_length: 8, precision: 53}, "@Ratio float "},
+		{Column{column_name: "Logged", data_type: "datetime2", max_length: 7, precision: 23, scale: 3}, "@Logged datetime2(3) "},
+		{Column{column_name: "Starts", data_type: "time", max_length: 5, precision: 16, scale: 7}, "@Starts time(7) "},
+		{Column{column_name: "Ver", data_type: "timestamp", max_length: 8}, "@Ver binary(8) "},
+		{Column{column_name: "Left", data_type: "date", max_length: 3, is_nullable: true}, "@Left date = NULL "},
+	}
+
+	for _, test := range tests {
+		if metaData := getMetaData(test.column); metaData != test.expected {
+			t.Errorf("getMetaData(%s %s) = %q, expected %q", test.column.column_name, test.column.data_type, metaData, test.expected)
+		}
+	}
+}
+
+func TestGetClassAddParameter(t *testing.T) {
+	tests := []struct {
+		column   Column
+		expected string
+	}{
+		{Column{column_name: "Name", data_type: "nvarchar"}, "cmd.Parameters.AddWithValue(\"@Name\", Name);"},
+		{Column{column_name: "Left", data_type: "date", is_nullable: true}, "cmd.Parameters.AddWithValue(\"@Left\", (object)Left ?? DBNull.Value);"},
+		{Column{column_name: "Node", data_type: "hierarchyid"}, "cmd.Parameters.Add(new SqlParameter(\"@Node\", SqlDbType.Udt) { UdtTypeName = \"hierarchyid\", Value = Node });"},
+		{Column{column_name: "Hash", data_type: "binary", max_length: 32}, "cmd.Parameters.Add(\"@Hash\", SqlDbType.Binary, 32).Value = Hash;"},
+		{Column{column_name: "Photo", data_type: "varbinary", max_length: -1, is_nullable: true}, "cmd.Parameters.Add(\"@Photo\", SqlDbType.VarBinary, -1).Value = (object)Photo ?? DBNull.Value;"},
+		{Column{column_name: "Scan", data_type: "image", max_length: 16, is_nullable: true}, "cmd.Parameters.Add(\"@Scan\", SqlDbType.VarBinary, -1).Value = (object)Scan ?? DBNull.Value;"},
+	}
+
+	for _, test := range tests {
+		if code := getClassAddParameter(test.column); code != test.expected {
+			t.Errorf("getClassAddParameter(%s) = %q, expected %q", test.column.column_name, code, test.expected)
+		}
+	}
+}
+
+func TestGetClassUsings(t *testing.T) {
+	dataTable := DataTable{name: "Site", columns: []Column{
+		{column_name: "SiteId", data_type: "int"},
+		{column_name: "Location", data_type: "geography"},
+		{column_name: "Node", data_type: "hierarchyid"},
+	}}
+
+	if usings := getClassUsings(dataTable); !reflect.DeepEqual(usings, []string{"Microsoft.SqlServer.Types"}) {
+		t.Errorf("getClassUsings() = %q, expected Microsoft.SqlServer.Types once", usings)
+	}
+}
+
+func TestSortColumns(t *testing.T) {
+	dataTable := DataTable{schema: "dbo", name: "Doc", columns: []Column{
+		{column_name: "DocId", data_type: "int"},
+		{column_name: "Body", data_type: "ntext"},
+		{column_name: "Meta", data_type: "xml"},
+		{column_name: "Title", data_type: "nvarchar", max_length: -1},
+		{column_name: "Location", data_type: "geography"},
+	}}
+
+	names := make([]string, 0)
+	for _, column := range dataTable.SortColumns() {
+		names = append(names, column.column_name)
+	}
+	if expected := []string{"DocId", "Title"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("SortColumns() = %q, expected %q", names, expected)
+	}
+}
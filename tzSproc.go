@@ -1,27 +1,34 @@
 package main
 
 import (
-	"bytes"
 	"database/sql"
 	"flag"
 	"fmt"
-	_ "github.com/denisenkom/go-mssqldb"
 	"log"
 	"os"
+	"path"
+	"sort"
 	"strings"
 )
 
 var debug = flag.Bool("debug", false, "enable debugging")
 var server = flag.String("server", "fecsql03", "the database server")
 var database = flag.String("database", "Internal", "the database ")
-var table = flag.String("table", "", "the database dataTable")
+var table = flag.String("table", "", "comma-separated list of tables to generate, schema.table for tables outside dbo, patterns allowed (Employee*, hr.%Audit)")
+var all = flag.Bool("all", false, "generate for every user table in the database")
 var user = flag.String("user", "SPWebProg", "the database user")
 var password = flag.String("password", "", "the user password")
 var port = flag.Int("port", 1433, "the database port")
+var schemaFile = flag.String("schema-file", "", "read the table details from a JSON or YAML snapshot instead of the database")
+var sqlStyle = flag.String("sql-style", "drop-create", "how the sprocs are replaced, drop-create or create-or-alter (SqlServer 2016 SP1 and up)")
+var saveStyle = flag.String("save", "choose", "how the C# Save() works, choose picks Insert() or Update() itself, upsert calls stp_<table>_upsert")
+var ddl = flag.String("ddl", "", "read the table details from CREATE TABLE scripts, a comma-separated list of .sql files or directories")
 
 type DataTable struct {
+	schema  string
 	name    string
 	columns []Column
+	grants  map[string][]string // who already has EXECUTE on the table's sprocs, by sproc name
 }
 
 type Column struct {
@@ -30,714 +37,376 @@ type Column struct {
 	data_type      string
 	max_length     int
 	precision      int
+	scale          int
 	column_id      int
 	is_identity    bool
 	is_computed    bool
+	is_nullable    bool
+	key_ordinal    int    // position in the primary key, 0 if it's not part of it
+	default_value  string // the DEFAULT expression as written, i.e. (getdate())
+	member_name    string // the C# member and sproc parameter name, set by setMemberNames
 }
 
 func main() {
 	flag.Parse() // parse the command line args
-	processDataTable("EmployeeIT")
-}
-
-// getConnectionString returns connection string for the SqlServer
-func getConnectionString() string {
-	connString := fmt.Sprintf("server=%s;port=%d;database=%s;user=%s;password=%s", *server, *port, *database, *user, *password)
-	return connString
-}
 
-// Given a column, return the SQL Parameter information
-// i.e. @hourlyWage decimal(10,3)
-func getMetaData(column Column) string {
-	// char, varchar, nvarchar, nchar, decimal, float, numeric
-	var buffer bytes.Buffer
-
-	size := ""
-
-	switch column.data_type {
-	case "char", "varchar", "nvarchar":
-		size = fmt.Sprintf("(%d)", column.max_length)
-	case "decimal", "float", "numeric":
-		size = fmt.Sprintf("(%d, %d)", column.max_length, column.precision)
+	if *sqlStyle != "drop-create" && *sqlStyle != "create-or-alter" {
+		log.Fatalf("unknown -sql-style %q, expected drop-create or create-or-alter", *sqlStyle)
 	}
-
-	buffer.WriteString(fmt.Sprintf("@%s %s%s ", column.column_name, column.data_type, size))
-
-	return buffer.String()
-}
-
-func check(e error) {
-	if e != nil {
-		panic(e)
+	if *saveStyle != "choose" && *saveStyle != "upsert" {
+		log.Fatalf("unknown -save %q, expected choose or upsert", *saveStyle)
 	}
-}
-
-// processDataTable calls the functions that generate the code
-func processDataTable(dataTableName string) {
-	dataTable := loadDataTable(dataTableName)
 
-	sprocs := makeSqlCode(dataTable)
-	class := makeClassCode(dataTable)
-	sprocFile, err := os.Create(fmt.Sprintf("CREATE_%s.sql", dataTableName))
-	check(err)
-
-	defer sprocFile.Close()
-
-	_, err = sprocFile.WriteString(sprocs)
-	sprocFile.Sync()
-
-	classFile, err := os.Create(fmt.Sprintf("C:\\client\\Current\\Common\\Internal\\Internal\\%s.cs", dataTableName))
-	check(err)
-	defer classFile.Close()
-
-	_, err = classFile.WriteString(class)
-	classFile.Sync()
+	var err error
+	if templates, err = loadTemplates(*templateDir); err != nil {
+		log.Fatal(err)
+	}
 
+	switch flag.Arg(0) {
+	case "", "generate":
+		generate()
+	case "diff":
+		diffMode = true
+		generate()
+	case "deploy":
+		deploy()
+	case "drift":
+		drift()
+	case "snapshot":
+		takeSnapshot(flag.Arg(1))
+	default:
+		log.Fatalf("unknown command %q, expected generate, diff, drift, deploy or snapshot", flag.Arg(0))
+	}
 }
 
-// loadDataTable grabs the dataTable and column details from the database
-func loadDataTable(dataTableName string) DataTable {
-	dataTable := DataTable{}
-	dataTable.name = dataTableName
-
-	connString := getConnectionString()
-	conn, err := sql.Open("mssql", connString)
-
+// generate writes the sprocs and class for each table picked by -table or -all
+func generate() {
+	provider, err := newSchemaProvider()
 	if err != nil {
-		log.Fatal("Open connection failed:", err.Error())
+		log.Fatal(err)
 	}
-	defer conn.Close()
-
-	sql := `select a.name as dataTable_name, b.name as column_name, c.name as data_type, 
-		b.max_length, b.precision, b.column_id,  b.is_identity, b.is_computed
-	from sys.objects a join sys.columns b
-		on b.object_id = a.object_id
-		join sys.types c
-			on c.user_type_id = b.user_type_id
-	where a.type = 'u'
-	and a.name = ?
-	order by a.name, b.column_id`
+	defer provider.Close()
 
-	stmt, err := conn.Prepare(sql)
+	dataTableNames, unmatched, err := findDataTables(provider)
 	if err != nil {
-		log.Fatal("prepare failed:", err.Error())
+		log.Fatal(err)
+	}
+	if len(dataTableNames) == 0 && len(unmatched) == 0 {
+		log.Fatal("no tables matched; use -table or -all")
 	}
 
-	defer stmt.Close()
-
-	rows, err := stmt.Query(dataTableName)
-
-	var column Column
+	// the rollback scripts need the sprocs as they're deployed now
+	var conn *sql.DB
+	if *rollback {
+		if conn, err = openConnection(); err != nil {
+			log.Fatalf("Open connection failed: %s", err)
+		}
+		defer conn.Close()
+	}
 
-	for rows.Next() {
-		err = rows.Scan(&column.dataTable_name, &column.column_name, &column.data_type, &column.max_length, &column.precision,
-			&column.column_id, &column.is_identity, &column.is_computed)
+	failed := make([]string, 0)
+	for _, pattern := range unmatched {
+		log.Printf("%s: no tables matched", pattern)
+		failed = append(failed, pattern)
+	}
+	for _, dataTableName := range dataTableNames {
+		dataTable, err := loadDataTable(provider, dataTableName)
+		if err == nil {
+			err = processDataTable(dataTable, conn)
+		}
 		if err != nil {
-			log.Fatal("Scan Failed:", err.Error())
+			log.Printf("%s: %s", dataTableName, err)
+			failed = append(failed, dataTableName)
 		}
-		dataTable.columns = append(dataTable.columns, column)
-
 	}
 
-	return dataTable
-
-}
-
-// makeClassCode generates the code for a C# class to call the sprocs
-func makeClassCode(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	// header code
-	buffer.WriteString(makeClassHeader(dataTable))
-
-	// constructor - initialize the members, because the database is kinda funky
-	// and doesn't handle null data very well
-	buffer.WriteString(makeClassConstructor(dataTable))
-
-	// getter / setter code
-	buffer.WriteString(makeClassGetSets(dataTable))
-
-	// save code 	-- it decides if it's an insert or update
-	buffer.WriteString(makeClassSave(dataTable))
-
-	// insert code
-	buffer.WriteString(makeClassInsert(dataTable))
-
-	// update code
-	buffer.WriteString(makeClassUpdate(dataTable))
-
-	// load parameters
-	buffer.WriteString(makeClassParameters(dataTable))
-
-	// delete code
-	buffer.WriteString(makeClassDelete(dataTable))
-
-	// load code -- based on identity key
-	buffer.WriteString(makeClassLoad(dataTable))
-
-	// loadFromRow()
-	buffer.WriteString(makeClassLoadFromRow(dataTable))
-
-	// footer code
-	buffer.WriteString(makeClassFooter())
-	buffer.WriteString(pp(3, fmt.Sprintf("Where is the sutff%s", "?")))
-	return buffer.String()
-}
-
-// makeClassLoadFromRow generates the code to load class from a row
-func makeClassLoadFromRow(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	tl := 2
-
-	buffer.WriteString(pp(tl, "public bool loadFromRow(DataRow row)\n"))
-	buffer.WriteString(pp(tl, "{\n"))
-
-	tl = 3
-
-	buffer.WriteString(pp(tl, "bool bResult = false;\n\n"))
-
-	for _, column := range dataTable.columns {
-		buffer.WriteString(pp(tl, getClassDataAssignment(column)))
+	// keep stdout clean for the code when it's being piped somewhere
+	report := os.Stdout
+	if *toStdout {
+		report = os.Stderr
 	}
 
-	buffer.WriteString(pp(tl, "bResult = true;\n"))
-	buffer.WriteString(pp(tl, "return bResult;\n"))
-	buffer.WriteString(pp(tl-1, "}\n"))
-
-	return buffer.String()
-}
-
-// makeClassLoad selects and loads a record based on the identity field
-func makeClassLoad(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	identity := getIdentityField(dataTable)
-	tl := 2
-
-	buffer.WriteString(pp(tl, "public bool Load()\n"))
-	buffer.WriteString(pp(tl, "{\n"))
-
-	tl = 3
-	buffer.WriteString(pp(tl, "bool bResult = false;\n"))
-	buffer.WriteString(pp(tl, "SqlConnection conn = getConnection();\n"))
-	buffer.WriteString(pp(tl, "conn.Open();\n"))
-
-	sproc := fmt.Sprintf("SqlCommand cmd = new SqlCommand(\"stp_%s_sel\", conn);\n", dataTable.name)
-	buffer.WriteString(pp(tl, sproc))
-
-	buffer.WriteString(pp(tl, "cmd.CommandType = CommandType.StoredProcedure;\n"))
-	buffer.WriteString(pp(tl, fmt.Sprintf("cmd.Parameters.AddWithValue(\"@%s\", %s);\n\n", identity, identity)))
-	buffer.WriteString(pp(tl, "DataTable dt = new DataTable();\n"))
-	buffer.WriteString(pp(tl, "dt.Load(cmd.ExecuteReader());\n"))
-	buffer.WriteString(pp(tl, "if (dt.Rows.Count > 0)\n"))
-	buffer.WriteString(pp(tl+1, "bResult = loadFromRow(dt.Rows[0]);\n"))
-	buffer.WriteString(pp(tl, "conn.Close();\n"))
-	buffer.WriteString(pp(tl, "return bResult;\n"))
-	buffer.WriteString(pp(tl, "}\n"))
-
-	return buffer.String()
+	total := len(dataTableNames) + len(unmatched)
+	fmt.Fprintf(report, "generated %d of %d tables\n", total-len(failed), total)
+	if len(failed) > 0 {
+		fmt.Fprintf(report, "failed: %s\n", strings.Join(failed, ", "))
+		os.Exit(1)
+	}
+	if diffMode {
+		fmt.Fprintf(report, "%d files differ\n", differences)
+		if differences > 0 {
+			os.Exit(1)
+		}
+	}
 }
 
-// makeClassDelete runs the delete sproc
-func makeClassDelete(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	identity := getIdentityField(dataTable)
-	parmString := fmt.Sprintf("cmd.Parameters.AddWithValue(\"@%s\", %s);\n", identity, identity)
-
-	tl := 2
-
-	buffer.WriteString(pp(tl, "public void Delete()\n"))
-	buffer.WriteString(pp(tl, "{\n"))
-	tl = 3
+// takeSnapshot dumps the table details to a snapshot file, every table
+// unless -table says otherwise
+func takeSnapshot(fileName string) {
+	provider, err := newSchemaProvider()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer provider.Close()
 
-	buffer.WriteString(pp(tl, "SqlConnection conn = getConnection();\n"))
-	buffer.WriteString(pp(tl, "conn.Open();\n"))
+	if *table == "" {
+		*all = true
+	}
 
-	buffer.WriteString(pp(tl, fmt.Sprintf("SqlCommand cmd = new SqlCommand(\"stp_%s_del\", conn);\n", dataTable.name)))
-	buffer.WriteString(pp(tl, "cmd.CommandType = CommandType.StoredProcedure;\n\n"))
-	buffer.WriteString(pp(tl, parmString))
-	buffer.WriteString(pp(tl, "cmd.ExecuteNonQuery();\n"))
+	dataTableNames, unmatched, err := findDataTables(provider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(unmatched) > 0 {
+		log.Fatalf("no tables matched %s", strings.Join(unmatched, ", "))
+	}
 
-	buffer.WriteString(pp(tl-1, "}\n\n"))
+	snapshot := schemaSnapshot{Server: *server, Database: *database}
+	for _, dataTableName := range dataTableNames {
+		dataTable, err := provider.LoadDataTable(dataTableName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		snapshot.Tables = append(snapshot.Tables, newTableSnapshot(dataTable))
+	}
 
-	return buffer.String()
+	if err := writeSnapshot(fileName, snapshot); err != nil {
+		log.Fatal(err)
+	}
 }
 
-//makeClassParameters will generate the parameters and append them to your cmd object
-func makeClassParameters(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	identity := getIdentityField(dataTable)
-
-	tl := 2
-
-	buffer.WriteString(pp(tl, "private void addParameters(SqlCommand cmd, bool isUpdate = false)\n"))
-	buffer.WriteString(pp(tl, "{\n"))
+// isFlagSet tells us if a flag was given on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// findDataTables works out which tables to generate from the -table and -all flags.
+// Plain names are used as is, anything with a wildcard is matched against the
+// tables the provider knows about. unmatched has the patterns that matched nothing.
+func findDataTables(provider SchemaProvider) (dataTableNames []string, unmatched []string, err error) {
+	names := make([]string, 0)
+	patterns := make([]string, 0)
+	for _, name := range strings.Split(*table, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if isPattern(name) {
+			patterns = append(patterns, name)
+		} else {
+			names = append(names, name)
+		}
+	}
 
-	tl = 3
+	if !*all && len(patterns) == 0 {
+		return names, nil, nil
+	}
 
-	buffer.WriteString(pp(tl, "if (isUpdate)\n"))
-	buffer.WriteString(pp(tl+1, fmt.Sprintf("cmd.Parameters.AddWithValue(\"@%s\", %s);\n", identity, identity)))
+	listed, err := provider.ListDataTables()
+	if err != nil {
+		return nil, nil, err
+	}
+	if *all {
+		return listed, nil, nil
+	}
 
-	tempText := ""
-	for _, column := range dataTable.columns {
-		// we don't want to process any identity or computedcolumns.
-		if !(column.is_identity || column.is_computed) {
-			tempText = fmt.Sprintf("cmd.Parameters.AddWithValue(\"@%s\", %s);\n", column.column_name, column.column_name)
-			buffer.WriteString(pp(tl, tempText))
+	// a table can be named and matched by a pattern as well, it's only done once
+	seen := make(map[string]bool)
+	for _, name := range names {
+		schema, name := splitTableName(name)
+		seen[strings.ToLower(schema+"."+name)] = true
+	}
 
+	unmatched = make([]string, 0)
+	for _, pattern := range patterns {
+		matched := matchDataTables([]string{pattern}, listed)
+		if len(matched) == 0 {
+			unmatched = append(unmatched, pattern)
+		}
+		for _, name := range matched {
+			if !seen[strings.ToLower(name)] {
+				seen[strings.ToLower(name)] = true
+				names = append(names, name)
+			}
 		}
 	}
-
-	buffer.WriteString(pp(tl-1, "}\n\n"))
-
-	return buffer.String()
+	return names, unmatched, nil
 }
 
-// makeClassInsert will generate the code to call the insert sproc
-func makeClassInsert(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	tl := 2
-	buffer.WriteString(pp(tl, "private int Insert()\n"))
-	buffer.WriteString(pp(tl, "{\n"))
-	tl = 3
-	buffer.WriteString(pp(tl, "int iReturn = 0;\n"))
-	buffer.WriteString(pp(tl, "SqlConnection conn = getConnection();\n"))
-	buffer.WriteString(pp(tl, "conn.Open();\n"))
-	buffer.WriteString(pp(tl, fmt.Sprintf("SqlCommand cmd = new SqlCommand(\"stp_%s_ins\", conn);\n", dataTable.name)))
-	buffer.WriteString(pp(tl, "cmd.CommandType = CommandType.StoredProcedure;\n\n"))
-	buffer.WriteString(pp(tl, "addParameters(cmd, false);\n\n"))
-	buffer.WriteString(pp(tl, "iReturn = Convert.ToInt32(cmd.ExecuteScalar());\n"))
-	buffer.WriteString(pp(tl, "return iReturn;\n"))
-
-	buffer.WriteString(pp(tl-1, "}\n\n"))
-
-	return buffer.String()
+// isPattern tells us if the -table name has a wildcard and needs the table list to
+// resolve. An _ on its own doesn't count, there are too many tables named like_this.
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?%")
 }
 
-// makeClassUpdate will generate the code to call the update sproc
-func makeClassUpdate(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	tl := 2
-	buffer.WriteString(pp(tl, "private int Update()\n"))
-	buffer.WriteString(pp(tl, "{\n"))
-	tl = 3
-	buffer.WriteString(pp(tl, "int iReturn = 0;\n"))
-	buffer.WriteString(pp(tl, "SqlConnection conn = getConnection();\n"))
-	buffer.WriteString(pp(tl, "conn.Open();\n"))
-	buffer.WriteString(pp(tl, fmt.Sprintf("SqlCommand cmd = new SqlCommand(\"stp_%s_upd\", conn);\n", dataTable.name)))
-	buffer.WriteString(pp(tl, "cmd.CommandType = CommandType.StoredProcedure;\n\n"))
-	buffer.WriteString(pp(tl, "addParameters(cmd, true);\n\n"))
-	buffer.WriteString(pp(tl, "cmd.ExecuteNonQuery();\n"))
-	buffer.WriteString(pp(tl, "return iReturn;\n"))
-
-	buffer.WriteString(pp(tl-1, "}\n\n"))
-
-	return buffer.String()
-}
-
-// makeClassFunctionDoc will create the summary comments that proceed a function
-func makeClassFunctionDoc(tabs int, functionDesc string) string {
-	var buffer bytes.Buffer
+// matchDataTables returns the schema.table names matched by any of the patterns.
+// Glob (* and ?) and LIKE (% and _) wildcards both work, and like SqlServer
+// the match ignores case. A pattern without a schema matches tables in any schema.
+func matchDataTables(patterns []string, dataTableNames []string) []string {
+	matched := make(map[string]bool)
 
-	buffer.WriteString(pp(tabs, "/// <summary>\n"))
-	buffer.WriteString(pp(tabs, fmt.Sprintf("/// %s\n", functionDesc)))
-	buffer.WriteString(pp(tabs, "/// </summary>\n"))
-	buffer.WriteString(pp(tabs, "/// <returns></returns>\n"))
+	for _, pattern := range patterns {
+		glob := strings.ToLower(strings.NewReplacer("%", "*", "_", "?").Replace(pattern))
+		for _, name := range dataTableNames {
+			candidate := name
+			if !strings.Contains(pattern, ".") {
+				_, candidate = splitTableName(name)
+			}
+			if ok, _ := path.Match(glob, strings.ToLower(candidate)); ok {
+				matched[name] = true
+			}
+		}
+	}
 
-	return buffer.String()
+	result := make([]string, 0, len(matched))
+	for name := range matched {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
 }
 
-func pp(tabs int, text string) string {
-
-	var buffer bytes.Buffer
-	buffer.WriteString(fmt.Sprintf("%s%s", strings.Repeat("\t", tabs), text))
-	return buffer.String()
+// processDataTable calls the functions that generate the code. The rollback
+// script is only written when there's a connection to read the deployed sprocs from.
+func processDataTable(dataTable DataTable, conn *sql.DB) error {
+	dataTableName := dataTable.name
 
-}
+	if !hasKey(dataTable) {
+		log.Printf("warning: %s has no primary key or identity column, only generating the insert", dataTableName)
+	}
 
-func getIdentityField(dataTable DataTable) string {
+	sprocs, err := makeSqlCode(dataTable)
+	if err != nil {
+		return err
+	}
+	class, err := makeClassCode(dataTable)
+	if err != nil {
+		return err
+	}
 
-	for _, value := range dataTable.columns {
-		if value.is_identity {
-			return value.column_name
+	if conn != nil {
+		if err := writeRollback(conn, dataTable, sprocs); err != nil {
+			return err
 		}
 	}
-	return ""
-}
-
-// makeClassSave decides if we're inserting or updating and calls the right function
-func makeClassSave(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	identity := getIdentityField(dataTable)
-
-	tl := 2
-
-	buffer.WriteString(makeClassFunctionDoc(tl, "Save() will decide to call insert or update for you."))
-
-	buffer.WriteString(pp(tl, "public int Save()\n"))
-	buffer.WriteString(pp(tl, "{\n"))
-
-	tl = 3
-
-	buffer.WriteString(pp(tl, "int iReturn = 0;\n"))
-
-	buffer.WriteString(pp(tl, fmt.Sprintf("if (%s > 0)\n", identity)))
-
-	buffer.WriteString(pp(tl, "{\n"))
-
-	tl = 4
-
-	buffer.WriteString(pp(tl, "Update();\n"))
-
-	buffer.WriteString(pp(tl, fmt.Sprintf("iReturn = %s;\n", identity)))
-	tl = 3
-
-	buffer.WriteString(pp(tl, "}\n"))
-	buffer.WriteString(pp(tl, "else\n"))
-
-	tl = 4
-	buffer.WriteString(pp(tl, "iReturn = Insert();\n"))
-
-	tl = 3
-	buffer.WriteString(pp(tl, "return iReturn;\n"))
-
-	tl = 2
-
-	buffer.WriteString(pp(tl, "}\n"))
+	if err := writeOutput(getSqlFileName(dataTable), sprocs); err != nil {
+		return err
+	}
 
-	return buffer.String()
+	// keep the hand-written code in the existing class
+	classFileName := getClassFileName(dataTable)
+	if class, err = preserveCustomRegions(classFileName, class); err != nil {
+		return err
+	}
+	return writeOutput(classFileName, class)
 }
 
-// makeClassFooter writes some utility functions and closes the class
-func makeClassFooter() string {
-	var buffer bytes.Buffer
-
-	buffer.WriteString("\t\tpublic SqlConnection getConnection() {\n\t\t\n")
-	buffer.WriteString("\t\t\t")
-	buffer.WriteString(fmt.Sprintf(`SqlConnection conn = Database.getSqlConnection("%s");`, *database))
-	buffer.WriteString("\n\t\t\treturn conn;\n")
-	buffer.WriteString("\t\t}\n")
-	buffer.WriteString("\t}\n}")
-
-	return buffer.String()
+// makeClassCode generates the code for a C# class to call the sprocs
+func makeClassCode(dataTable DataTable) (string, error) {
+	return executeTemplate("class.tmpl", dataTable)
 }
 
-// getClassDataTypeDefault returns the varialbe initilizer
-func getClassDataAssignment(column Column) string {
-	name := column.column_name
-	switch column.data_type {
-	case "char", "varchar", "nvarchar", "nchar":
-		return fmt.Sprintf("%s = row[\"%s\"].ToString();\n", name, name)
-	case "text":
-		return fmt.Sprintf("%s = row[\"%s\"].ToString();\n", name, name)
-	case "smallint", "int", "bigint":
-		return fmt.Sprintf("%s = Convert.ToInt32(row[\"%s\"]);\n", name, name)
-	case "datetime", "smalldatetime":
-		return fmt.Sprintf("%s = Convert.ToDateTime(row[\"%s\"].ToString());\n", name, name)
-	case "bit":
-		return fmt.Sprintf("%s = Convert.ToBoolean(row[\"%s\"].ToString());\n", name, name)
-	case "decimal", "numeric":
-		return fmt.Sprintf("%s = Convert.ToDecimal(row[\"%s\"].ToString());\n", name, name)
-	case "float":
-		return fmt.Sprintf("%s = Convert.ToFloat(row[\"%s\"].ToString());\n", name, name)
-	}
-	return ""
+// makeSqlCode generates the sprocs for the table
+func makeSqlCode(dataTable DataTable) (string, error) {
+	return executeTemplate("sql.tmpl", dataTable)
 }
 
-// getClassDataTypeDefault returns the varialbe initilizer
-func getClassDataTypeDefault(column Column) string {
-	switch column.data_type {
-	case "char", "varchar", "nvarchar", "nchar":
-		return "string.Empty"
-	case "text":
-		return "string.Empty" // TODO: fix this for reals, ya'll
-	case "smallint", "int", "bigint":
-		return "0"
-	case "datetime", "smalldatetime":
-		return `DateTime.Parse("1/1/1900")`
-	case "bit":
-		return "false"
-	case "decimal", "numeric":
-		return "0.0"
-	case "float":
-		return "0.0"
-	}
-	return "string.Empty"
+// makeSqlInsert generates the insert sproc
+func makeSqlInsert(dataTable DataTable) (string, error) {
+	return executeTemplate("sql_insert.tmpl", dataTable)
 }
 
-// getClassDataType returns the C# data type for a sql data type
-func getClassDataType(column Column) string {
-
-	switch column.data_type {
-	case "char", "varchar", "nvarchar", "nchar":
-		return "string"
-	case "text":
-		return "string" // TODO: fix this for reals, ya'll
-	case "smallint", "int", "bigint":
-		return "int"
-	case "datetime", "smalldatetime":
-		return "DateTime"
-	case "bit":
-		return "bool"
-	case "decimal", "numeric":
-		return "decimal"
-	case "float":
-		return "float"
-
-	}
-	return "string"
+// makeSqlUpdate generates the update sproc
+func makeSqlUpdate(dataTable DataTable) (string, error) {
+	return executeTemplate("sql_update.tmpl", dataTable)
 }
 
-// makeClasConstructor generates the constructor code for the class
-func makeClassConstructor(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	var tl = "\t\t" // the tab level that we're currently at
-	buffer.WriteString(fmt.Sprintf("%spublic %s()\n", tl, dataTable.name))
-	buffer.WriteString(fmt.Sprintf("%s{\n", tl))
-	tl = "\t\t\t"
+// makeSqlDelete generates the delete sproc
+func makeSqlDelete(dataTable DataTable) (string, error) {
+	return executeTemplate("sql_delete.tmpl", dataTable)
+}
 
-	for _, value := range dataTable.columns {
-		// we don't want to process any identity columns.
-		if !value.is_identity {
-			buffer.WriteString(fmt.Sprintf("%s%s = %s;\n", tl, value.column_name, getClassDataTypeDefault(value)))
-		}
-	}
-	tl = "\t\t"
-	buffer.WriteString(fmt.Sprintf("%s}\n\n", tl))
-	return buffer.String()
+// makeSqlSelect generates the select sproc
+func makeSqlSelect(dataTable DataTable) (string, error) {
+	return executeTemplate("sql_select.tmpl", dataTable)
 }
 
-// makeClassGetSets generates the gettors and settors for the class
-func makeClassGetSets(dataTable DataTable) string {
-	var buffer bytes.Buffer
-	var tl = "\t\t"
+// defaultSchema is where a table lives if its name doesn't say otherwise
+const defaultSchema = "dbo"
 
-	for _, value := range dataTable.columns {
-		buffer.WriteString(fmt.Sprintf("%spublic %s %s { get; set; }\n", tl, getClassDataType(value), value.column_name))
+// splitTableName breaks a name like hr.Employee or [hr].[Employee] into the
+// schema and table, the schema is dbo if there isn't one
+func splitTableName(name string) (string, string) {
+	schema := defaultSchema
+	if i := strings.Index(name, "."); i >= 0 {
+		schema, name = name[:i], name[i+1:]
 	}
-	buffer.WriteString("\n")
-	return buffer.String()
+	return strings.Trim(schema, "[]"), strings.Trim(name, "[]")
 }
 
-// makeClassHeader generates the header information for the class
-func makeClassHeader(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	buffer.WriteString("using System;\nusing System.Collections.Generic;\nusing System.Data;\n")
-	buffer.WriteString("using System.Data.SqlClient;\nusing FECUtil;\n\n")
-
-	buffer.WriteString(fmt.Sprintf("namespace %s {\n", *database))
-
-	functionDoc := fmt.Sprintf("this class is used for all common functionality for a record in the\n\t/// %s dataTable in the %s database on the %s server\n", *table, *database, *server)
-
-	// TODO: convert to pp() here
-	buffer.WriteString(makeClassFunctionDoc(1, functionDoc))
-
-	buffer.WriteString(fmt.Sprintf("\tpublic class %s\n\t{\n", dataTable.name))
-
-	return buffer.String()
+// getSchemaName returns the schema the table lives in
+func getSchemaName(dataTable DataTable) string {
+	if dataTable.schema == "" {
+		return defaultSchema
+	}
+	return dataTable.schema
 }
 
-// makeSqlCode generates the code for Sql Server sprocs
-func makeSqlCode(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	// write the use clause
-	buffer.WriteString(fmt.Sprintf("use %s\n\n", *database))
-
-	// insert sproc
-	buffer.WriteString("\n-- ******** INSERT ********\n")
-	buffer.WriteString(makeSqlInsert(dataTable))
-
-	// update sproc
-	buffer.WriteString("\n-- ******** UPDATE ********\n")
-	buffer.WriteString(makeSqlUpdate(dataTable))
-
-	// delete sproc
-	buffer.WriteString("\n-- ******** DELETE ********\n")
-	buffer.WriteString(makeSqlDelete(dataTable))
-
-	// select sproc
-	buffer.WriteString("\n-- ******** READ ********\n")
-	buffer.WriteString(makeSqlSelect(dataTable))
-
-	return buffer.String()
-
+// getDataTableFullName returns the schema.table name, i.e. hr.Employee
+func getDataTableFullName(dataTable DataTable) string {
+	return getSchemaName(dataTable) + "." + dataTable.name
 }
 
-// makeSqlDrop generates the code to drop a sproc if it exists
-func makeSqlDropStatement(sprocName string) string {
-	sql := "if exists (select name from sysobjects where name = '%s')\n\tdrop proc %s\ngo"
-	return fmt.Sprintf(sql, sprocName, sprocName)
-
+// quoteName brackets a SQL identifier, like QUOTENAME does
+func quoteName(name string) string {
+	return "[" + strings.Replace(name, "]", "]]", -1) + "]"
 }
 
-// makeSqlUpdate returns the text for creating an update sproc
-func makeSqlUpdate(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	parameters := make([]string, 0)
-	fields := make([]string, 0)
-	var whereClause string
-
-	sprocName := fmt.Sprintf("stp_%s_upd", dataTable.name)
-
-	buffer.WriteString(makeSqlDropStatement(sprocName))
-	buffer.WriteString(fmt.Sprintf("\nCREATE proc %s \n", sprocName))
-
-	// loop through the columns and create the SET clause and the WHERE clause
-	for _, value := range dataTable.columns {
-		// we don't want to process any computed columns.
-		if !value.is_computed {
-			metaData := getMetaData(value)
-			parameters = append(parameters, "\t"+metaData)
-			if !value.is_identity {
-				fields = append(fields, fmt.Sprintf("%s = @%s", value.column_name, value.column_name))
-			} else {
-				whereClause = fmt.Sprintf("WHERE %s = @%s", value.column_name, value.column_name)
-			}
-		}
-	}
-
-	buffer.WriteString(strings.Join(parameters, ",\n"))
-
-	buffer.WriteString("\nAS\n")
-
-	buffer.WriteString(fmt.Sprintf("update %s\n", dataTable.name))
-	buffer.WriteString(fmt.Sprintf("SET %s", strings.Join(fields, ", ")))
-	buffer.WriteString(fmt.Sprintf("\n%s", whereClause))
-	buffer.WriteString("\ngo\n")
-	return buffer.String()
-
-}
-
-// makeSqlDelete returns the text for creating a delete sproc
-func makeSqlDelete(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	var parameter string
-	var whereClause string
-
-	sprocName := fmt.Sprintf("stp_%s_del", dataTable.name)
-
-	buffer.WriteString(makeSqlDropStatement(sprocName))
-	buffer.WriteString(fmt.Sprintf("\nCREATE proc %s \n", sprocName))
-
-	// print the parameters
-	for _, value := range dataTable.columns {
-		// this is an insert clause, so skip the ones that are an identity column
-		// don't write directly to the buffer - we end up with the
-		// 'too many commas' problem. Simpler to use a strings.join
-		// than to try to remove the last comma.
-		if value.is_identity {
-			metaData := getMetaData(value)
-			parameter = "\t" + metaData
-			whereClause = fmt.Sprintf("WHERE %s = @%s", value.column_name, value.column_name)
+// getKeyColumns returns the primary key columns in key order. Tables without a
+// primary key fall back to the identity column, if there is one.
+func getKeyColumns(dataTable DataTable) []Column {
+	keyColumns := make([]Column, 0)
 
+	for _, column := range dataTable.columns {
+		if column.key_ordinal > 0 {
+			keyColumns = append(keyColumns, column)
 		}
 	}
+	sort.Slice(keyColumns, func(i, j int) bool { return keyColumns[i].key_ordinal < keyColumns[j].key_ordinal })
 
-	buffer.WriteString(parameter)
-
-	buffer.WriteString("\nAS\n")
-
-	buffer.WriteString(fmt.Sprintf("DELETE FROM %s\n", dataTable.name))
-	buffer.WriteString(fmt.Sprintf("\n%s", whereClause))
-	buffer.WriteString("\ngo\n")
-	return buffer.String()
-
-}
-
-// makeSqlSelect returns the text for creating a select sproc
-// it assumes a identity field as a primary key
-func makeSqlSelect(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	fields := make([]string, 0)
-	var parameter string
-	var whereClause string
-
-	sprocName := fmt.Sprintf("stp_%s_sel", dataTable.name)
-
-	buffer.WriteString(makeSqlDropStatement(sprocName))
-	buffer.WriteString(fmt.Sprintf("\nCREATE proc %s \n", sprocName))
-
-	// print the parameters
-	for _, value := range dataTable.columns {
-		// this is an insert clause, so skip the ones that are an identity column
-		// don't write directly to the buffer - we end up with the
-		// 'too many commas' problem. Simpler to use a strings.join
-		// than to try to remove the last comma.
-		if !value.is_computed {
-			if value.is_identity {
-				parameter = getMetaData(value)
-				whereClause = fmt.Sprintf("WHERE %s = @%s", value.column_name, value.column_name)
-			} else {
-				fields = append(fields, fmt.Sprintf("%s", value.column_name))
+	if len(keyColumns) == 0 {
+		for _, column := range dataTable.columns {
+			if column.is_identity {
+				keyColumns = append(keyColumns, column)
 			}
 		}
 	}
 
-	buffer.WriteString(fmt.Sprintf("\t%s", parameter))
-	buffer.WriteString("\nAS\n")
-
-	buffer.WriteString(fmt.Sprintf("SELECT %s\n", strings.Join(fields, ", ")))
-	buffer.WriteString(fmt.Sprintf("FROM %s\n", dataTable.name))
-	buffer.WriteString(whereClause)
-	buffer.WriteString("\ngo\n")
-	return buffer.String()
+	return keyColumns
+}
 
+// hasKey tells us if the table has a primary key or identity to find a record by
+func hasKey(dataTable DataTable) bool {
+	return len(getKeyColumns(dataTable)) > 0
 }
 
-// makeSqlInsert returns the text for creating an insert sproc
-func makeSqlInsert(dataTable DataTable) string {
-	var buffer bytes.Buffer
-
-	parameters := make([]string, 0)
-	fields := make([]string, 0)
-	parms := make([]string, 0) // could be created by manipulating fields, but these are so small it doesn't matter
-	var outputParm string
-	var outputParmName string
-
-	sprocName := fmt.Sprintf("stp_%s_ins", dataTable.name)
-
-	buffer.WriteString(makeSqlDropStatement(sprocName))
-
-	buffer.WriteString(fmt.Sprintf("\nCREATE proc %s \n", sprocName))
-
-	// print the parameters
-	for _, value := range dataTable.columns {
-		// this is an insert clause, so skip the ones that are an identity column
-		// don't write directly to the buffer - we end up with the
-		// 'too many commas' problem. Simpler to use a strings.join
-		// than to try to remove the last comma.
-		if !(value.is_identity || value.is_computed) {
-			metaData := getMetaData(value)
-			parameters = append(parameters, "\t"+metaData)
-			fields = append(fields, value.column_name)
-			parms = append(parms, "@"+value.column_name)
-		} else {
-			if value.is_identity {
-				outputParmName = "@" + value.column_name
-				outputParm = fmt.Sprintf("%s OUTPUT", getMetaData(value))
-			}
+// isKeyColumn tells us if the column is one of the key fields
+func isKeyColumn(dataTable DataTable, column Column) bool {
+	for _, keyColumn := range getKeyColumns(dataTable) {
+		if keyColumn.column_name == column.column_name {
+			return true
 		}
 	}
+	return false
+}
 
-	buffer.WriteString(strings.Join(parameters, ",\n"))
-	buffer.WriteString(fmt.Sprintf(",\n\t%s", outputParm))
-	buffer.WriteString("\nAS\n")
-	buffer.WriteString(fmt.Sprintf("insert into %s (%s)\n", dataTable.name, strings.Join(fields, ", ")))
-	buffer.WriteString(fmt.Sprintf("\nVALUES (%s)", strings.Join(parms, ", ")))
-	buffer.WriteString(fmt.Sprintf("\nSET %s = scope_identity()", outputParmName))
-	buffer.WriteString("\ngo\n")
-	return buffer.String()
-
+// isRowVersion tells us if SqlServer sets the column on every insert and update,
+// it can't be written to but it tells us if the record changed since it was read
+func isRowVersion(column Column) bool {
+	return column.data_type == "timestamp" || column.data_type == "rowversion"
 }
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata with the generated code")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	var err error
+	if templates, err = loadTemplates(""); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+// employeeTable has an identity key, nullable and computed columns
+func employeeTable() DataTable {
+	return DataTable{schema: "dbo", name: "Employee", columns: []Column{
+		{column_name: "EmployeeId", data_type: "int", column_id: 1, is_identity: true, key_ordinal: 1},
+		{column_name: "Name", data_type: "nvarchar", max_length: 100, column_id: 2},
+		{column_name: "HourlyWage", data_type: "decimal", precision: 10, scale: 3, column_id: 3},
+		{column_name: "HireDate", data_type: "date", column_id: 4, is_nullable: true},
+		{column_name: "FullName", data_type: "nvarchar", max_length: 202, column_id: 5, is_computed: true, is_nullable: true},
+	}}
+}
+
+// orderLineTable has a two column natural key
+func orderLineTable() DataTable {
+	return DataTable{schema: "sales", name: "OrderLine", columns: []Column{
+		{column_name: "OrderId", data_type: "int", column_id: 1, key_ordinal: 1},
+		{column_name: "LineNo", data_type: "smallint", column_id: 2, key_ordinal: 2},
+		{column_name: "Quantity", data_type: "int", column_id: 3},
+		{column_name: "Price", data_type: "money", column_id: 4, is_nullable: true},
+	}}
+}
+
+// appLogTable has no key at all
+func appLogTable() DataTable {
+	return DataTable{schema: "dbo", name: "AppLog", columns: []Column{
+		{column_name: "Logged", data_type: "datetime2", scale: 3, column_id: 1},
+		{column_name: "Message", data_type: "nvarchar", max_length: -1, column_id: 2, is_nullable: true},
+	}}
+}
+
+// empRoleTable is a junction table, every column is in the key
+func empRoleTable() DataTable {
+	return DataTable{schema: "dbo", name: "EmpRole", columns: []Column{
+		{column_name: "EmpId", data_type: "int", column_id: 1, key_ordinal: 1},
+		{column_name: "RoleId", data_type: "int", column_id: 2, key_ordinal: 2},
+	}}
+}
+
+// accountTable has a rowversion for optimistic concurrency
+func accountTable() DataTable {
+	return DataTable{schema: "dbo", name: "Account", columns: []Column{
+		{column_name: "AccountId", data_type: "bigint", column_id: 1, is_identity: true, key_ordinal: 1},
+		{column_name: "Balance", data_type: "money", column_id: 2},
+		{column_name: "RowVer", data_type: "timestamp", column_id: 3},
+	}}
+}
+
+// checkGolden compares the generated code with testdata/fileName, -update rewrites it
+func checkGolden(t *testing.T, fileName string, generated string) {
+	t.Helper()
+	fileName = filepath.Join("testdata", fileName)
+
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fileName, []byte(generated), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(expected) != generated {
+		t.Errorf("%s is out of date, run go test -update if the change is intended\n%s", fileName,
+			unifiedDiff(fileName, "generated", string(expected), generated))
+	}
+}
+
+func TestGolden(t *testing.T) {
+	provider := newMemoryProvider(employeeTable(), orderLineTable(), appLogTable(), empRoleTable(), accountTable())
+
+	dataTableNames, err := provider.ListDataTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dataTableName := range dataTableNames {
+		dataTable, err := loadDataTable(provider, dataTableName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sprocs, err := makeSqlCode(dataTable)
+		if err != nil {
+			t.Fatalf("%s: %s", dataTableName, err)
+		}
+		checkGolden(t, dataTableName+".sql", sprocs)
+
+		class, err := makeClassCode(dataTable)
+		if err != nil {
+			t.Fatalf("%s: %s", dataTableName, err)
+		}
+		checkGolden(t, dataTableName+".cs", class)
+	}
+}
+
+func TestMatchDataTables(t *testing.T) {
+	dataTableNames := []string{"dbo.Employee", "dbo.EmployeeAudit", "hr.Employee", "sales.OrderLine", "sales.OrderLineAudit"}
+
+	tests := []struct {
+		patterns []string
+		expected []string
+	}{
+		{[]string{"Employee*"}, []string{"dbo.Employee", "dbo.EmployeeAudit", "hr.Employee"}},
+		{[]string{"%audit"}, []string{"dbo.EmployeeAudit", "sales.OrderLineAudit"}},
+		{[]string{"hr.*"}, []string{"hr.Employee"}},
+		{[]string{"dbo.Employee?????"}, []string{"dbo.EmployeeAudit"}},
+		{[]string{"Order_ine"}, []string{"sales.OrderLine"}},
+		{[]string{"Employee", "%Line"}, []string{"dbo.Employee", "hr.Employee", "sales.OrderLine"}},
+		{[]string{"Nope*"}, []string{}},
+	}
+
+	for _, test := range tests {
+		if matched := matchDataTables(test.patterns, dataTableNames); !reflect.DeepEqual(matched, test.expected) {
+			t.Errorf("matchDataTables(%q) = %q, expected %q", test.patterns, matched, test.expected)
+		}
+	}
+}
+
+func TestSqlStyles(t *testing.T) {
+	defer func(saved string) { *sqlStyle = saved }(*sqlStyle)
+
+	*sqlStyle = "create-or-alter"
+	code, err := makeSqlCode(employeeTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(code, "drop proc") {
+		t.Errorf("create-or-alter shouldn't drop the sprocs, it loses their grants:\n%s", code)
+	}
+	if n := strings.Count(code, "CREATE OR ALTER PROCEDURE"); n == 0 || n != len(findSprocs(code)) {
+		t.Errorf("create-or-alter gave %d CREATE OR ALTER PROCEDUREs for %d sprocs:\n%s", n, len(findSprocs(code)), code)
+	}
+}
+
+func TestSaveStyles(t *testing.T) {
+	defer func(saved string) { *saveStyle = saved }(*saveStyle)
+
+	for _, style := range []string{"choose", "upsert"} {
+		*saveStyle = style
+		for _, dataTable := range []DataTable{employeeTable(), orderLineTable(), appLogTable(), empRoleTable()} {
+			setMemberNames(&dataTable)
+			class, err := makeClassCode(dataTable)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// a table without a key can only insert, whatever the style, and one
+			// that's all key has nothing to update, so it always upserts
+			upsert := hasKey(dataTable) && (style == "upsert" || len(dataTable.SetColumns()) == 0)
+			if strings.Contains(class, dataTable.QualifiedSprocName("upsert")) != upsert {
+				t.Errorf("-save=%s: Save() for %s calls the upsert sproc: %t, expected %t", style, dataTable.name, !upsert, upsert)
+			}
+		}
+	}
+}
+
+func TestRowVersion(t *testing.T) {
+	account := accountTable()
+	if column := account.RowVersion(); column == nil || column.column_name != "RowVer" {
+		t.Fatalf("RowVersion() = %v, expected RowVer", column)
+	}
+	if column := employeeTable().RowVersion(); column != nil {
+		t.Errorf("Employee has no rowversion, RowVersion() = %v", column)
+	}
+
+	// the server sets the rowversion, it's never written
+	for _, columns := range [][]Column{account.InsertColumns(), account.UpdateColumns(), account.SetColumns()} {
+		for _, column := range columns {
+			if isRowVersion(column) {
+				t.Errorf("%s shouldn't be written", column.column_name)
+			}
+		}
+	}
+
+	// the upsert doesn't let the last save win either
+	defer func(saved string) { *saveStyle = saved }(*saveStyle)
+	*saveStyle = "upsert"
+	setMemberNames(&account)
+	class, err := makeClassCode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if save := class[strings.Index(class, "public int Save()"):strings.Index(class, "private int Insert()")]; !strings.Contains(save, "DBConcurrencyException") {
+		t.Errorf("-save=upsert: Save() doesn't check the rowversion:\n%s", save)
+	}
+}
+
+func TestGetKeyColumns(t *testing.T) {
+	tests := []struct {
+		dataTable DataTable
+		expected  []string
+	}{
+		{employeeTable(), []string{"EmployeeId"}},
+		{orderLineTable(), []string{"OrderId", "LineNo"}},
+		// the key order comes from key_ordinal, not the column order
+		{DataTable{name: "t", columns: []Column{{column_name: "a", key_ordinal: 2}, {column_name: "b", key_ordinal: 1}}}, []string{"b", "a"}},
+		// without a primary key the identity will do
+		{DataTable{name: "t", columns: []Column{{column_name: "a"}, {column_name: "id", is_identity: true}}}, []string{"id"}},
+		{DataTable{name: "t", columns: []Column{{column_name: "a"}}}, []string{}},
+	}
+
+	for _, test := range tests {
+		keyNames := make([]string, 0)
+		for _, column := range getKeyColumns(test.dataTable) {
+			keyNames = append(keyNames, column.column_name)
+		}
+		if !reflect.DeepEqual(keyNames, test.expected) {
+			t.Errorf("getKeyColumns(%s) = %q, expected %q", test.dataTable.name, keyNames, test.expected)
+		}
+	}
+}
+
+func TestFindDataTables(t *testing.T) {
+	provider := newMemoryProvider(employeeTable(), DataTable{schema: "dbo", name: "EmployeeAudit"}, orderLineTable())
+	defer func(saved string) { *table = saved }(*table)
+	defer func(saved bool) { *all = saved }(*all)
+
+	tests := []struct {
+		table     string
+		all       bool
+		expected  []string
+		unmatched []string
+	}{
+		{"Employee", false, []string{"Employee"}, nil},
+		// _ is too common in table names to be a wildcard
+		{"sales.OrderLine, Nope_X", false, []string{"sales.OrderLine", "Nope_X"}, nil},
+		{"Employee,Emp*", false, []string{"Employee", "dbo.EmployeeAudit"}, []string{}},
+		{"Emp*,Nope*", false, []string{"dbo.Employee", "dbo.EmployeeAudit"}, []string{"Nope*"}},
+		{"", true, []string{"dbo.Employee", "dbo.EmployeeAudit", "sales.OrderLine"}, nil},
+	}
+
+	for _, test := range tests {
+		*table, *all = test.table, test.all
+		dataTableNames, unmatched, err := findDataTables(provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dataTableNames, test.expected) || !reflect.DeepEqual(unmatched, test.unmatched) {
+			t.Errorf("-table %s -all=%t found %q and %q unmatched, expected %q and %q", test.table, test.all, dataTableNames, unmatched, test.expected, test.unmatched)
+		}
+	}
+}
+
+func TestSplitTableName(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		table  string
+	}{
+		{"Employee", "dbo", "Employee"},
+		{"hr.Employee", "hr", "Employee"},
+		{"[hr].[Order Line]", "hr", "Order Line"},
+	}
+
+	for _, test := range tests {
+		if schema, table := splitTableName(test.name); schema != test.schema || table != test.table {
+			t.Errorf("splitTableName(%q) = %q, %q, expected %q, %q", test.name, schema, table, test.schema, test.table)
+		}
+	}
+}
+
+func TestIsPattern(t *testing.T) {
+	for name, expected := range map[string]bool{"Employee": false, "Order_Line": false, "Order%": true, "Emp*": true, "hr.Employee?": true} {
+		if isPattern(name) != expected {
+			t.Errorf("isPattern(%s) = %t, expected %t", name, !expected, expected)
+		}
+	}
+}
+
+func TestSqlDropQuoting(t *testing.T) {
+	dataTable := DataTable{schema: "dbo", name: "O'Brien"}
+
+	code, err := executeTemplate("sql_drop.tmpl", dataTable.QualifiedSprocName("ins"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "if object_id('[dbo].[stp_O''Brien_ins]', 'P') is not null\n\tdrop proc [dbo].[stp_O'Brien_ins]\ngo\n"; code != expected {
+		t.Errorf("sql_drop.tmpl gave\n%s\nexpected\n%s", code, expected)
+	}
+}